@@ -1,8 +1,10 @@
 package main
 
 import (
+	"expvar"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
@@ -13,9 +15,12 @@ import (
 	"github.com/golang/glog"
 	log "github.com/sirupsen/logrus"
 	"github.com/thetechnick/nginx-ingress/pkg/agent"
+	"github.com/thetechnick/nginx-ingress/pkg/apis/nginxingress/v1alpha1"
+	"github.com/thetechnick/nginx-ingress/pkg/collision"
 	"github.com/thetechnick/nginx-ingress/pkg/controller"
 	"github.com/thetechnick/nginx-ingress/pkg/storage/etcd"
 	"github.com/thetechnick/nginx-ingress/pkg/storage/local"
+	"github.com/thetechnick/nginx-ingress/pkg/validation"
 	"github.com/thetechnick/nginx-ingress/pkg/version"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
@@ -52,13 +57,69 @@ var (
 		`Specifies a configmaps resource that can be used to customize NGINX
 		configuration. The value must follow the following format: <namespace>/<name>`)
 
+	nginxConfig = flag.String("nginx-config", "",
+		`Specifies a NginxIngressConfig resource that is used to customize NGINX
+		configuration. Takes precedence over -nginx-configmaps, which is only used
+		while the resource does not exist.
+		The value must follow the following format: <namespace>/<name>`)
+
 	printVersion = flag.Bool("version", false, "Print version and exit")
 
 	selector = flag.String("selector", "",
 		`Selector (label query) to filter ingress objects on, supports the same syntax as kubectl`)
+
+	allowSnippetAnnotations = flag.Bool("allow-snippet-annotations", true,
+		`If false, the nginx.org/server-snippets and nginx.org/location-snippets annotations
+		are rejected. The directives allowed in snippet annotations can be restricted with the
+		snippet-directives-allowlist and snippet-directives-denylist ConfigMap keys.`)
+
+	validateConfig = flag.Bool("validate-config", true,
+		`If true, every server config is tested together with the main config using "nginx -t"
+		in a sandbox directory before it is published. Invalid configs are not published and
+		a warning event is recorded on the Ingress. Requires the nginx binary.`)
+
+	validationDir = flag.String("validation-dir", "",
+		`Directory in which the sandboxes for the config validation are created.
+		Defaults to the directory for temporary files.`)
+
+	ingressWorkers = flag.Int("ingress-workers", 4,
+		`Number of Ingress updates processed in parallel. Ingresses sharing hosts are
+		always updated one after another.`)
+
+	maxRetries = flag.Int("max-retries", 15,
+		`Number of retries of a failed update with exponential backoff before the controller gives up
+		and records a warning event. The update is retried on the next change. 0 retries forever.`)
+
+	strictCollisions = flag.Bool("strict-collisions", false,
+		`If true, an Ingress that conflicts with an older Ingress for the same host, e.g. declares
+		the same path with a different backend or a different certificate, is rejected instead of
+		merged. Conflicts are recorded as events on both Ingresses.`)
+
+	collisionPolicy = flag.String("collision-policy", string(collision.PolicyMerge),
+		`Policy for hosts declared by multiple Ingresses: "merge" merges all Ingresses,
+		"oldest-wins" uses only the oldest Ingress of a host and "namespace-owner" merges only
+		the Ingresses in the namespace of the oldest Ingress of a host. The oldest Ingress of a host
+		can override the policy with the nginx.org/collision-policy annotation.`)
+
+	mainTemplate = flag.String("main-template", "",
+		`Path to a custom template for the main config nginx.conf, the embedded template is used if empty.
+		The file is checked for changes and the config is rendered again, invalid templates are ignored.
+		The main-template key of the -nginx-configmaps ConfigMap takes precedence.`)
+
+	ingressTemplate = flag.String("ingress-template", "",
+		`Path to a custom template for the server configs of the Ingresses, the embedded template is used if empty.
+		The file is checked for changes and the config is rendered again, invalid templates are ignored.
+		The ingress-template key of the -nginx-configmaps ConfigMap takes precedence.`)
+
+	metricsAddress = flag.String("metrics-address", "",
+		`Address to serve the controller metrics on at /debug/vars, e.g. ":9100". Disabled if empty.`)
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		os.Exit(runRender(os.Args[2:]))
+	}
+
 	flag.Parse()
 	if *printVersion {
 		fmt.Printf("NGINX Ingress controller version: %s\n", version.Version)
@@ -111,6 +172,30 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to create client: %v.", err)
 	}
+	nicClient, err := v1alpha1.NewRESTClient(config)
+	if err != nil {
+		log.Fatalf("Failed to create NginxIngressConfig client: %v.", err)
+	}
+
+	var validator validation.Validator
+	if *validateConfig {
+		validator = validation.NewSandboxValidator(nil, *validationDir)
+	}
+
+	if *metricsAddress != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/debug/vars", expvar.Handler())
+			if err := http.ListenAndServe(*metricsAddress, mux); err != nil {
+				log.WithError(err).Error("Error serving metrics")
+			}
+		}()
+	}
+
+	collisionHandler, err := collision.NewCollisionHandler(collision.Policy(*collisionPolicy), *strictCollisions)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid collision policy")
+	}
 
 	var lbc *controller.LoadBalancerController
 	if *serverMode {
@@ -128,15 +213,27 @@ func main() {
 		mcs := etcd.NewMainConfigStorage(cli)
 		scs := etcd.NewServerConfigStorage(cli)
 
-		lbc, _ = controller.NewLoadBalancerController(
+		lbc, err = controller.NewLoadBalancerController(
 			kubeClient,
 			30*time.Second,
 			*watchNamespace,
 			k8sSelector,
 			*nginxConfigMaps,
+			nicClient,
+			*nginxConfig,
+			validator,
+			*allowSnippetAnnotations,
+			collisionHandler,
+			*mainTemplate,
+			*ingressTemplate,
+			*ingressWorkers,
+			*maxRetries,
 			mcs,
 			scs,
 		)
+		if err != nil {
+			log.WithError(err).Fatal("Error creating controller")
+		}
 		lbc.Run()
 
 		return
@@ -148,15 +245,27 @@ func main() {
 	mcs := local.NewMainConfigStorage(n)
 	scs := local.NewServerConfigStorage(n)
 
-	lbc, _ = controller.NewLoadBalancerController(
+	lbc, err = controller.NewLoadBalancerController(
 		kubeClient,
 		30*time.Second,
 		*watchNamespace,
 		k8sSelector,
 		*nginxConfigMaps,
+		nicClient,
+		*nginxConfig,
+		validator,
+		*allowSnippetAnnotations,
+		collisionHandler,
+		*mainTemplate,
+		*ingressTemplate,
+		*ingressWorkers,
+		*maxRetries,
 		mcs,
 		scs,
 	)
+	if err != nil {
+		log.WithError(err).Fatal("Error creating controller")
+	}
 
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, syscall.SIGTERM, syscall.SIGINT)
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetechnick/nginx-ingress/pkg/collision"
+	"github.com/thetechnick/nginx-ingress/pkg/controller"
+	"github.com/thetechnick/nginx-ingress/pkg/storage"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/memory"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+	"github.com/thetechnick/nginx-ingress/pkg/validation"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+const renderUsage = `Usage: lbc render -input-dir <dir> -output-dir <dir> [flags]
+
+Renders the nginx config for the ConfigMaps, Ingresses, Services, Endpoints and Secrets
+in the YAML or JSON files of the input directory, without a cluster.
+The main config is written to <output-dir>/nginx.conf, the server configs to <output-dir>/conf.d/.
+Warnings are printed to stdout.
+
+Flags:
+`
+
+// runRender implements the render subcommand and returns the exit code
+func runRender(args []string) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, renderUsage)
+		flags.PrintDefaults()
+	}
+	inputDir := flags.String("input-dir", "",
+		`Directory containing the YAML or JSON files of the objects, read recursively`)
+	outputDir := flags.String("output-dir", "",
+		`Directory the nginx config is written to, it is created if it does not exist`)
+	renderConfigMaps := flags.String("nginx-configmaps", "",
+		`ConfigMap with the nginx configuration in the format <namespace>/<name>, the defaults are used if empty`)
+	renderMainTemplate := flags.String("main-template", "",
+		`Path to a custom template for the main config nginx.conf, the embedded template is used if empty`)
+	renderIngressTemplate := flags.String("ingress-template", "",
+		`Path to a custom template for the server configs of the Ingresses, the embedded template is used if empty`)
+	renderAllowSnippetAnnotations := flags.Bool("allow-snippet-annotations", true,
+		`If false, the nginx.org/server-snippets and nginx.org/location-snippets annotations are rejected`)
+	renderCollisionPolicy := flags.String("collision-policy", string(collision.PolicyMerge),
+		`Policy for hosts declared by multiple Ingresses: "merge", "oldest-wins" or "namespace-owner"`)
+	renderStrictCollisions := flags.Bool("strict-collisions", false,
+		`If true, Ingresses that conflict with an older Ingress for the same host are rejected`)
+	renderValidateConfig := flags.Bool("validate-config", false,
+		`If true, every server config is tested with "nginx -t", invalid configs are not written. Requires the nginx binary.`)
+	renderValidationDir := flags.String("validation-dir", "",
+		`Directory in which the sandboxes for the config validation are created`)
+	renderLogLevel := flags.String("log-level", "warning",
+		`Log level can be one of "debug", "info", "warning", "error", "fatal"`)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *inputDir == "" || *outputDir == "" {
+		flags.Usage()
+		return 2
+	}
+
+	level, err := log.ParseLevel(*renderLogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid log level: %v\n", err)
+		return 2
+	}
+	log.SetLevel(level)
+	log.SetOutput(os.Stderr)
+
+	collisionHandler, err := collision.NewCollisionHandler(collision.Policy(*renderCollisionPolicy), *renderStrictCollisions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid collision policy: %v\n", err)
+		return 2
+	}
+	var validator validation.Validator
+	if *renderValidateConfig {
+		validator = validation.NewSandboxValidator(nil, *renderValidationDir)
+	}
+
+	templates := make([]string, 2)
+	for i, name := range []string{*renderMainTemplate, *renderIngressTemplate} {
+		if name == "" {
+			continue
+		}
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading template: %v\n", err)
+			return 1
+		}
+		templates[i] = string(content)
+	}
+
+	objects, err := loadObjects(*inputDir, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading objects: %v\n", err)
+		return 1
+	}
+
+	recorder := &printRecorder{out: os.Stdout}
+	mcs := memory.NewMainConfigStorage()
+	scs := memory.NewServerConfigStorage()
+	c, err := controller.NewOfflineController(
+		objects,
+		*renderConfigMaps,
+		templates[0],
+		templates[1],
+		validator,
+		*renderAllowSnippetAnnotations,
+		collisionHandler,
+		recorder,
+		mcs,
+		scs,
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading objects: %v\n", err)
+		return 1
+	}
+	if err = c.Render(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering config: %v\n", err)
+		return 1
+	}
+	if err = writeConfigs(*outputDir, mcs, scs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing config: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Config written to %s with %d warning(s)\n", *outputDir, recorder.warnings)
+	return 0
+}
+
+// loadObjects decodes the objects of all YAML and JSON files below dir,
+// objects of other kinds are skipped with a note printed to out
+func loadObjects(dir string, out io.Writer) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+	err := filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+		for {
+			doc, err := reader.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			docObjects, err := decodeObjects(doc)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			for _, obj := range docObjects {
+				if !supportedObject(obj) {
+					fmt.Fprintf(out, "Skipping %s in %s\n", reflect.TypeOf(obj).Elem().Name(), name)
+					continue
+				}
+				objects = append(objects, obj)
+			}
+		}
+	})
+	return objects, err
+}
+
+// decodeObjects decodes a YAML or JSON document, the items of lists are returned as separate objects.
+// Objects without namespace are put into the default namespace, like kubectl does.
+func decodeObjects(doc []byte) ([]runtime.Object, error) {
+	data, err := yaml.ToJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || string(trimmed) == "null" {
+		// empty document or only comments
+		return nil, nil
+	}
+
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if list, ok := obj.(*api_v1.List); ok {
+		objects := []runtime.Object{}
+		for _, item := range list.Items {
+			itemObjects, err := decodeObjects(item.Raw)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, itemObjects...)
+		}
+		return objects, nil
+	}
+
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if m.GetNamespace() == "" {
+		m.SetNamespace(metav1.NamespaceDefault)
+	}
+	return []runtime.Object{obj}, nil
+}
+
+func supportedObject(obj runtime.Object) bool {
+	switch obj.(type) {
+	case *api_v1.ConfigMap, *extensions.Ingress, *api_v1.Service, *api_v1.Endpoints, *api_v1.Secret:
+		return true
+	}
+	return false
+}
+
+// writeConfigs writes the stored configs into dir, the absolute paths of
+// the configs below /etc/nginx/ are written relative to dir
+func writeConfigs(dir string, mcs storage.MainConfigStorage, scs storage.ServerConfigStorage) error {
+	mainConfig, err := mcs.Get()
+	if err != nil {
+		return err
+	}
+	if mainConfig != nil {
+		if err = writeConfigFile(dir, path.Join(storage.MainConfigDir, "nginx.conf"), mainConfig.Config); err != nil {
+			return err
+		}
+		if err = writeConfigFiles(dir, mainConfig.Files); err != nil {
+			return err
+		}
+	}
+
+	serverConfigs, err := scs.List()
+	if err != nil {
+		return err
+	}
+	for _, serverConfig := range serverConfigs {
+		name := serverConfig.Name
+		if name == "" {
+			name = "default"
+		}
+		if err = writeConfigFile(dir, path.Join(storage.ServerConfigDir, name+".conf"), serverConfig.Config); err != nil {
+			return err
+		}
+		if err = writeConfigFiles(dir, serverConfig.Files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeConfigFiles(dir string, files []*pb.File) error {
+	for _, file := range files {
+		if err := writeConfigFile(dir, file.Name, file.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeConfigFile(dir, name string, content []byte) error {
+	p := filepath.Join(dir, strings.TrimPrefix(name, storage.MainConfigDir))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, content, 0600)
+}
+
+// printRecorder prints events instead of recording them in the cluster
+type printRecorder struct {
+	out      io.Writer
+	warnings int
+}
+
+func (r *printRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if eventtype == api_v1.EventTypeWarning {
+		r.warnings++
+	}
+
+	name := ""
+	if m, err := meta.Accessor(object); err == nil {
+		name = m.GetNamespace() + "/" + m.GetName()
+	}
+	fmt.Fprintf(r.out, "%s %s %s %s: %s\n",
+		eventtype, reflect.TypeOf(object).Elem().Name(), name, reason, message)
+}
+
+func (r *printRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *printRecorder) PastEventf(object runtime.Object, timestamp metav1.Time, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventtype, reason, messageFmt, args...)
+}
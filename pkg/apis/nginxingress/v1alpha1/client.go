@@ -0,0 +1,41 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+func init() {
+	// register the types with the client-go scheme,
+	// so events can be recorded on these objects
+	if err := AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
+}
+
+// NewRESTClient creates a REST client for the nginx.org api group
+func NewRESTClient(cfg *rest.Config) (*rest.RESTClient, error) {
+	config := *cfg
+	config.GroupVersion = &SchemeGroupVersion
+	config.APIPath = "/apis"
+	config.ContentType = runtime.ContentTypeJSON
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+
+	return rest.RESTClientFor(&config)
+}
+
+// UpdateStatus writes the status of the given NginxIngressConfig using the status subresource
+func UpdateStatus(client rest.Interface, cfg *NginxIngressConfig) (*NginxIngressConfig, error) {
+	result := &NginxIngressConfig{}
+	err := client.Put().
+		Namespace(cfg.Namespace).
+		Resource(NginxIngressConfigResource).
+		Name(cfg.Name).
+		SubResource("status").
+		Body(cfg).
+		Do().
+		Into(result)
+	return result, err
+}
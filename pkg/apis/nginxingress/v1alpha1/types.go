@@ -0,0 +1,142 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NginxIngressConfig configures the NGINX instances managed by the controller.
+// It is the typed replacement of the nginx ConfigMap.
+type NginxIngressConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NginxIngressConfigSpec   `json:"spec"`
+	Status NginxIngressConfigStatus `json:"status,omitempty"`
+}
+
+// NginxIngressConfigSpec holds the global NGINX configuration parameters,
+// unset fields keep their default values
+type NginxIngressConfigSpec struct {
+	HTTPSnippets     []string `json:"httpSnippets,omitempty"`
+	ServerSnippets   []string `json:"serverSnippets,omitempty"`
+	LocationSnippets []string `json:"locationSnippets,omitempty"`
+
+	// SnippetDirectivesAllowlist restricts the directives usable in snippet annotations
+	SnippetDirectivesAllowlist []string `json:"snippetDirectivesAllowlist,omitempty"`
+	// SnippetDirectivesDenylist lists directives that must not be used in snippet annotations
+	SnippetDirectivesDenylist []string `json:"snippetDirectivesDenylist,omitempty"`
+
+	ServerTokens              *bool  `json:"serverTokens,omitempty"`
+	ServerNamesHashBucketSize string `json:"serverNamesHashBucketSize,omitempty"`
+	ServerNamesHashMaxSize    string `json:"serverNamesHashMaxSize,omitempty"`
+	LogFormat                 string `json:"logFormat,omitempty"`
+	WorkerShutdownTimeout     string `json:"workerShutdownTimeout,omitempty"`
+	WorkerProcesses           string `json:"workerProcesses,omitempty"`
+	WorkerConnections         int64  `json:"workerConnections,omitempty"`
+	WorkerRLimitNofile        int64  `json:"workerRLimitNofile,omitempty"`
+	WorkerCPUAffinity         string `json:"workerCPUAffinity,omitempty"`
+	ErrorLogLevel             string `json:"errorLogLevel,omitempty"`
+	KeepaliveTimeout          string `json:"keepaliveTimeout,omitempty"`
+	KeepaliveRequests         int64  `json:"keepaliveRequests,omitempty"`
+	AccessLogPath             string `json:"accessLogPath,omitempty"`
+	AccessLogOff              bool   `json:"accessLogOff,omitempty"`
+	HTTP2                     *bool  `json:"http2,omitempty"`
+	H2C                       *bool  `json:"h2c,omitempty"`
+	RedirectToHTTPS           *bool  `json:"redirectToHTTPS,omitempty"`
+	ClientMaxBodySize         string `json:"clientMaxBodySize,omitempty"`
+
+	ProxyConnectTimeout  string   `json:"proxyConnectTimeout,omitempty"`
+	ProxyReadTimeout     string   `json:"proxyReadTimeout,omitempty"`
+	ProxyBuffering       *bool    `json:"proxyBuffering,omitempty"`
+	ProxyBuffers         string   `json:"proxyBuffers,omitempty"`
+	ProxyBufferSize      string   `json:"proxyBufferSize,omitempty"`
+	ProxyMaxTempFileSize string   `json:"proxyMaxTempFileSize,omitempty"`
+	ProxyProtocol        *bool    `json:"proxyProtocol,omitempty"`
+	ProxyHideHeaders     []string `json:"proxyHideHeaders,omitempty"`
+	ProxyPassHeaders     []string `json:"proxyPassHeaders,omitempty"`
+
+	HSTS        *HSTSSpec        `json:"hsts,omitempty"`
+	RealIP      *RealIPSpec      `json:"realIP,omitempty"`
+	SSL         *SSLSpec         `json:"ssl,omitempty"`
+	Compression *CompressionSpec `json:"compression,omitempty"`
+}
+
+// HSTSSpec configures HTTP Strict Transport Security
+type HSTSSpec struct {
+	Enabled           bool   `json:"enabled"`
+	MaxAge            *int64 `json:"maxAge,omitempty"`
+	IncludeSubdomains bool   `json:"includeSubdomains,omitempty"`
+}
+
+// RealIPSpec configures the realip module
+// http://nginx.org/en/docs/http/ngx_http_realip_module.html
+type RealIPSpec struct {
+	Header    string   `json:"header,omitempty"`
+	SetFrom   []string `json:"setFrom,omitempty"`
+	Recursive bool     `json:"recursive,omitempty"`
+}
+
+// SSLSpec configures the ssl module
+// http://nginx.org/en/docs/http/ngx_http_ssl_module.html
+type SSLSpec struct {
+	Protocols           []string `json:"protocols,omitempty"`
+	PreferServerCiphers bool     `json:"preferServerCiphers,omitempty"`
+	Ciphers             string   `json:"ciphers,omitempty"`
+	DHParam             string   `json:"dhparam,omitempty"`
+}
+
+// CompressionSpec configures the gzip and brotli modules, brotli requires the ngx_brotli module
+// http://nginx.org/en/docs/http/ngx_http_gzip_module.html
+type CompressionSpec struct {
+	Gzip          *bool    `json:"gzip,omitempty"`
+	GzipLevel     *int64   `json:"gzipLevel,omitempty"`
+	GzipMinLength string   `json:"gzipMinLength,omitempty"`
+	GzipTypes     []string `json:"gzipTypes,omitempty"`
+	Brotli        bool     `json:"brotli,omitempty"`
+}
+
+// NginxIngressConfigStatus reports the state of the NginxIngressConfig as seen by the controller
+type NginxIngressConfigStatus struct {
+	// ObservedGeneration is the last generation parsed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// RenderedGeneration is the last generation rendered into the main config
+	RenderedGeneration int64 `json:"renderedGeneration,omitempty"`
+	// Errors lists the fields of the observed generation that were skipped
+	Errors []string `json:"errors,omitempty"`
+}
+
+// NginxIngressConfigList is a list of NginxIngressConfig objects
+type NginxIngressConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NginxIngressConfig `json:"items"`
+}
+
+// The types below are used to work around a known problem with custom
+// resources and ugorji, they make sure the objects are decoded by encoding/json.
+
+type nginxIngressConfigCopy NginxIngressConfig
+type nginxIngressConfigListCopy NginxIngressConfigList
+
+// UnmarshalJSON decodes the object using encoding/json
+func (c *NginxIngressConfig) UnmarshalJSON(data []byte) error {
+	tmp := nginxIngressConfigCopy{}
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	*c = NginxIngressConfig(tmp)
+	return nil
+}
+
+// UnmarshalJSON decodes the list using encoding/json
+func (l *NginxIngressConfigList) UnmarshalJSON(data []byte) error {
+	tmp := nginxIngressConfigListCopy{}
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	*l = NginxIngressConfigList(tmp)
+	return nil
+}
@@ -0,0 +1,128 @@
+package collision
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// declaration identifies a part of a server that can conflict with other servers of the same host
+type declaration struct {
+	Kind ConflictKind
+	Name string
+}
+
+// declarations returns the values of all declarations of the server
+func declarations(server *config.Server) map[declaration]string {
+	decls := map[declaration]string{}
+	for _, location := range server.Locations {
+		decls[declaration{ConflictLocation, location.Path}] = upstreamServers(location.Upstream)
+	}
+
+	if server.SSL {
+		decls[declaration{ConflictCertificate, ""}] = certificate(server)
+	}
+
+	settings := map[string]bool{
+		"http2":             server.HTTP2,
+		"hsts":              server.HSTS,
+		"redirect-to-https": server.RedirectToHTTPS,
+		"proxy-protocol":    server.ProxyProtocol,
+		"server-tokens":     server.ServerTokens,
+		"use-gzip":          server.Gzip,
+		"use-brotli":        server.Brotli,
+	}
+	for name, value := range settings {
+		decls[declaration{ConflictSetting, name}] = strconv.FormatBool(value)
+	}
+	if server.HSTS {
+		decls[declaration{ConflictSetting, "hsts-max-age"}] = strconv.FormatInt(server.HSTSMaxAge, 10)
+		decls[declaration{ConflictSetting, "hsts-include-subdomains"}] = strconv.FormatBool(server.HSTSIncludeSubdomains)
+	}
+	if server.Gzip {
+		decls[declaration{ConflictSetting, "gzip-level"}] = strconv.FormatInt(server.GzipLevel, 10)
+	}
+	if server.Gzip || server.Brotli {
+		decls[declaration{ConflictSetting, "gzip-min-length"}] = server.GzipMinLength
+		decls[declaration{ConflictSetting, "gzip-types"}] = strings.Join(server.GzipTypes, ",")
+	}
+	return decls
+}
+
+// upstreamServers returns the sorted addresses of the upstream,
+// upstream names differ between ingress objects even if they point to the same service
+func upstreamServers(upstream config.Upstream) string {
+	servers := []string{}
+	for _, server := range upstream.UpstreamServers {
+		servers = append(servers, server.Address+":"+server.Port)
+	}
+	sort.Strings(servers)
+	return strings.Join(servers, ",")
+}
+
+// certificate returns the content of the certificate file of the server
+func certificate(server *config.Server) string {
+	for _, file := range server.Files {
+		if file.Name == server.SSLCertificate {
+			return string(file.Content)
+		}
+	}
+	return server.SSLCertificate
+}
+
+// declarationOwners tracks which ingress object declared the merged value of a declaration
+type declarationOwners map[declaration]*v1beta1.Ingress
+
+// conflicts compares the declarations of the base server, the server merged into it and the merge result.
+// The winner of a conflict is the ingress whose value ended up in the merged server.
+func (owners declarationOwners) conflicts(
+	host string,
+	base, merge, merged map[declaration]string,
+	ing *v1beta1.Ingress,
+) []Conflict {
+	conflicts := []Conflict{}
+	for decl, value := range merge {
+		baseValue, ok := base[decl]
+		if !ok || baseValue == value {
+			continue
+		}
+
+		conflict := Conflict{
+			Host:   host,
+			Kind:   decl.Kind,
+			Name:   decl.Name,
+			Winner: owners[decl],
+			Loser:  ing,
+		}
+		if merged[decl] == value {
+			conflict.Winner, conflict.Loser = ing, owners[decl]
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Kind != conflicts[j].Kind {
+			return conflicts[i].Kind < conflicts[j].Kind
+		}
+		return conflicts[i].Name < conflicts[j].Name
+	})
+	return conflicts
+}
+
+// claim records the ingress as owner of all declarations
+// it contributed to the merged server
+func (owners declarationOwners) claim(base, merge, merged map[declaration]string, ing *v1beta1.Ingress) {
+	for decl, value := range merge {
+		if merged[decl] != value {
+			continue
+		}
+		if baseValue, ok := base[decl]; ok && baseValue == value && owners[decl] != nil {
+			// the value was declared before
+			continue
+		}
+		owners[decl] = ing
+	}
+}
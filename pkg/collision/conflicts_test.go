@@ -0,0 +1,123 @@
+package collision
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func TestConflicts(t *testing.T) {
+	older := &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "older",
+			Namespace:         "team-a",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+	}
+	newer := &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "newer",
+			Namespace:         "team-b",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-1 * time.Hour)),
+		},
+	}
+	server := func(upstreamAddress string, cert string, http2 bool) *config.Server {
+		upstream := config.Upstream{
+			Name:            "upstream-" + upstreamAddress,
+			UpstreamServers: []config.UpstreamServer{{Address: upstreamAddress, Port: "80"}},
+		}
+		return &config.Server{
+			Name: "one.example.com",
+			Locations: []config.Location{
+				{Path: "/", Upstream: upstream},
+			},
+			SSL:            true,
+			SSLCertificate: "/etc/nginx/ssl/one.example.com.pem",
+			Files: []*pb.File{
+				{Name: "/etc/nginx/ssl/one.example.com.pem", Content: []byte(cert)},
+			},
+			HTTP2: http2,
+		}
+	}
+
+	t.Run("no conflicts for identical declarations", func(t *testing.T) {
+		assert := assert.New(t)
+		merged, err := NewMergingCollisionHandler().Resolve(MergeList{
+			{Ingress: older, Servers: []*config.Server{server("10.0.0.1", "cert", false)}},
+			{Ingress: newer, Servers: []*config.Server{server("10.0.0.1", "cert", false)}},
+		})
+
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Empty(merged[0].Conflicts)
+			assert.Len(merged[0].Ingress, 2)
+		}
+	})
+
+	t.Run("reports conflicting declarations", func(t *testing.T) {
+		assert := assert.New(t)
+		merged, err := NewMergingCollisionHandler().Resolve(MergeList{
+			{Ingress: newer, Servers: []*config.Server{server("10.0.0.2", "cert-b", true)}},
+			{Ingress: older, Servers: []*config.Server{server("10.0.0.1", "cert-a", false)}},
+		})
+
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Equal([]Conflict{
+				{Host: "one.example.com", Kind: ConflictCertificate, Winner: newer, Loser: older},
+				{Host: "one.example.com", Kind: ConflictLocation, Name: "/", Winner: newer, Loser: older},
+				{Host: "one.example.com", Kind: ConflictSetting, Name: "http2", Winner: newer, Loser: older},
+			}, merged[0].Conflicts)
+			assert.Len(merged[0].Ingress, 2)
+			assert.Equal("10.0.0.2", merged[0].Server.Locations[0].Upstream.UpstreamServers[0].Address)
+			assert.Equal(
+				`location "/" of host "one.example.com" conflicts between team-b/newer and team-a/older, the declaration of team-b/newer is used`,
+				merged[0].Conflicts[1].Error())
+		}
+	})
+
+	t.Run("reports conflicting compression settings", func(t *testing.T) {
+		assert := assert.New(t)
+		gzip := func(level int64) *config.Server {
+			s := server("10.0.0.1", "cert", false)
+			s.Gzip = true
+			s.GzipLevel = level
+			s.GzipMinLength = "20"
+			return s
+		}
+		merged, err := NewMergingCollisionHandler().Resolve(MergeList{
+			{Ingress: older, Servers: []*config.Server{gzip(1)}},
+			{Ingress: newer, Servers: []*config.Server{gzip(9)}},
+		})
+
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Equal([]Conflict{
+				{Host: "one.example.com", Kind: ConflictSetting, Name: "gzip-level", Winner: older, Loser: newer},
+			}, merged[0].Conflicts)
+			assert.Equal(int64(1), merged[0].Server.GzipLevel)
+		}
+	})
+
+	t.Run("strict mode rejects the newer ingress", func(t *testing.T) {
+		assert := assert.New(t)
+		merged, err := NewStrictCollisionHandler().Resolve(MergeList{
+			{Ingress: older, Servers: []*config.Server{server("10.0.0.1", "cert", false)}},
+			{Ingress: newer, Servers: []*config.Server{server("10.0.0.2", "cert", false)}},
+		})
+
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Equal([]Conflict{
+				{Host: "one.example.com", Kind: ConflictLocation, Name: "/", Winner: older, Loser: newer, Rejected: true},
+			}, merged[0].Conflicts)
+			assert.Equal([]*v1beta1.Ingress{older}, merged[0].Ingress)
+			assert.Equal([]*v1beta1.Ingress{newer}, merged[0].Rejected)
+			assert.Equal("10.0.0.1", merged[0].Server.Locations[0].Upstream.UpstreamServers[0].Address)
+			assert.Equal(
+				`location "/" of host "one.example.com" conflicts between team-a/older and team-b/newer, team-b/newer is rejected`,
+				merged[0].Conflicts[0].Error())
+		}
+	})
+}
@@ -1,7 +1,10 @@
 package collision
 
 import (
+	"fmt"
+
 	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
 	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
 )
 
@@ -21,7 +24,7 @@ func (list collisionContextList) Len() int {
 	return len(list)
 }
 func (list collisionContextList) Less(i, j int) bool {
-	return list[i].Ingress.CreationTimestamp.Before(list[j].Ingress.CreationTimestamp)
+	return olderThan(&list[i].Ingress, &list[j].Ingress)
 }
 func (list collisionContextList) Swap(i, j int) {
 	list[i], list[j] = list[j], list[i]
@@ -40,14 +43,88 @@ func (list MergeList) Len() int {
 	return len(list)
 }
 func (list MergeList) Less(i, j int) bool {
-	return list[i].Ingress.CreationTimestamp.Before(list[j].Ingress.CreationTimestamp)
+	return olderThan(list[i].Ingress, list[j].Ingress)
 }
 func (list MergeList) Swap(i, j int) {
 	list[i], list[j] = list[j], list[i]
 }
 
+// olderThan checks if the ingress a was created before b. The creation timestamps
+// only have a resolution of one second, ties are broken by namespace, name and UID
+// so the order does not depend on the order the ingress objects are listed in.
+func olderThan(a, b *v1beta1.Ingress) bool {
+	if !a.CreationTimestamp.Equal(b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.UID < b.UID
+}
+
 // MergedIngressConfig is the result of merging the server configs of multiple ingress object into one
 type MergedIngressConfig struct {
 	Ingress []*v1beta1.Ingress
 	Server  *config.Server
+	// Rejected are the ingress objects declaring the host that were not merged,
+	// they are merged again when the ingress objects they conflict with are gone
+	Rejected []*v1beta1.Ingress
+	// Conflicts between the declarations of the ingress objects
+	Conflicts []Conflict
+	// Warnings are invalid declarations that were skipped,
+	// wrapped in the context of the ingress object declaring them
+	Warnings []errors.ErrObjectContext
+}
+
+// ConflictKind is the kind of declaration multiple ingress objects disagree on
+type ConflictKind string
+
+// Kinds of conflicts between ingress objects declaring the same host
+const (
+	// ConflictLocation is the same path with a different upstream
+	ConflictLocation ConflictKind = "location"
+	// ConflictCertificate is a different certificate for the host
+	ConflictCertificate ConflictKind = "certificate"
+	// ConflictSetting is a contradictory server setting
+	ConflictSetting ConflictKind = "setting"
+	// ConflictHost is a host owned by another ingress according to the collision policy
+	ConflictHost ConflictKind = "host"
+)
+
+// Conflict is a declaration of a host that two ingress objects disagree on
+type Conflict struct {
+	Host string
+	Kind ConflictKind
+	// Name is the path of the location, the name of the setting or the collision policy of the host
+	Name string
+	// Winner is the ingress whose declaration is used
+	Winner *v1beta1.Ingress
+	// Loser is the ingress whose declaration is shadowed
+	Loser *v1beta1.Ingress
+	// Rejected is true if the losing ingress was not merged into the server at all
+	Rejected bool
+}
+
+func (c Conflict) Error() string {
+	if c.Kind == ConflictHost {
+		return fmt.Sprintf("host %q is owned by %s according to the %s collision policy, %s is rejected",
+			c.Host, ingressKey(c.Winner), c.Name, ingressKey(c.Loser))
+	}
+	declaration := fmt.Sprintf("%s %q", c.Kind, c.Name)
+	if c.Kind == ConflictCertificate {
+		declaration = string(c.Kind)
+	}
+	if c.Rejected {
+		return fmt.Sprintf("%s of host %q conflicts between %s and %s, %s is rejected",
+			declaration, c.Host, ingressKey(c.Winner), ingressKey(c.Loser), ingressKey(c.Loser))
+	}
+	return fmt.Sprintf("%s of host %q conflicts between %s and %s, the declaration of %s is used",
+		declaration, c.Host, ingressKey(c.Winner), ingressKey(c.Loser), ingressKey(c.Winner))
+}
+
+func ingressKey(ing *v1beta1.Ingress) string {
+	return ing.Namespace + "/" + ing.Name
 }
@@ -5,24 +5,42 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
 	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
 	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
 )
 
 type mergingCollisionHandler struct {
 	log *log.Entry
+	// policy decides which ingress objects may declare a host
+	policy Policy
+	// strict rejects ingress objects that conflict with older ones instead of merging them
+	strict bool
 }
 
 // NewMergingCollisionHandler returns a CollisionHandler
-// which merges the declaration of multiple ingress objects
+// which merges the declaration of multiple ingress objects,
+// newer ingress objects override the locations and certificates of older ones
 func NewMergingCollisionHandler() Handler {
 	return &mergingCollisionHandler{
-		log: log.WithField("module", "MergingCollisionHandler"),
+		log:    log.WithField("module", "MergingCollisionHandler"),
+		policy: PolicyMerge,
+	}
+}
+
+// NewStrictCollisionHandler returns a CollisionHandler
+// which merges the declaration of multiple ingress objects,
+// but rejects an ingress object if it conflicts with an older one
+func NewStrictCollisionHandler() Handler {
+	return &mergingCollisionHandler{
+		log:    log.WithField("module", "StrictCollisionHandler"),
+		policy: PolicyMerge,
+		strict: true,
 	}
 }
 
 func (m *mergingCollisionHandler) Resolve(mergeList MergeList) (merged []MergedIngressConfig, err error) {
-	sort.Sort(mergeList)
+	sort.Stable(mergeList)
 	merged = []MergedIngressConfig{}
 
 	hosts := []string{}
@@ -53,24 +71,89 @@ func (m *mergingCollisionHandler) Resolve(mergeList MergeList) (merged []MergedI
 	m.log.WithField("ings", updatedIngressKeys).WithField("hosts", hosts).Debug("Merging configs")
 
 	for _, host := range hosts {
-		serverConfigs := hostServerConfigMap[host]
-		var baseServer config.Server
-		for i, server := range serverConfigs {
-			if i == 0 {
-				baseServer = *server
-			} else {
-				baseServer = *(m.mergeServers(baseServer, server))
-			}
+		merged = append(merged, m.mergeHost(host, hostServerConfigMap[host], hostIngressMap[host]))
+	}
+
+	return
+}
+
+// mergeHost merges the servers of a host, declared by the ingress objects at the same index.
+// Ingress objects that are not admitted by the policy of the host are rejected.
+func (m *mergingCollisionHandler) mergeHost(host string, servers []*config.Server, ingresses []*v1beta1.Ingress) MergedIngressConfig {
+	mergedConfig := MergedIngressConfig{
+		Ingress:   []*v1beta1.Ingress{},
+		Rejected:  []*v1beta1.Ingress{},
+		Conflicts: []Conflict{},
+		Warnings:  []errors.ErrObjectContext{},
+	}
+	owners := declarationOwners{}
+
+	hostOwner := ingresses[0]
+	policy, err := hostPolicy(m.policy, hostOwner)
+	if err != nil {
+		mergedConfig.Warnings = append(mergedConfig.Warnings, errors.WrapInObjectContext(err, hostOwner))
+		m.log.
+			WithField("host", host).
+			WithField("ingress", ingressKey(hostOwner)).
+			WithError(err).
+			Warn("Using default collision policy")
+	}
+
+	var baseServer config.Server
+	for i, server := range servers {
+		ing := ingresses[i]
+		if !admitted(policy, hostOwner, ing) {
+			mergedConfig.Conflicts = append(mergedConfig.Conflicts, Conflict{
+				Host:     host,
+				Kind:     ConflictHost,
+				Name:     string(policy),
+				Winner:   hostOwner,
+				Loser:    ing,
+				Rejected: true,
+			})
+			mergedConfig.Rejected = append(mergedConfig.Rejected, ing)
+			m.log.
+				WithField("host", host).
+				WithField("ingress", ingressKey(ing)).
+				WithField("owner", ingressKey(hostOwner)).
+				Warn("Rejecting ingress, the host is owned by another ingress")
+			continue
 		}
-		baseServer.Upstreams = m.getUpstreamsForServer(&baseServer)
-		mergedConfig := MergedIngressConfig{
-			Server:  &baseServer,
-			Ingress: hostIngressMap[host],
+		if i == 0 {
+			baseServer = *server
+			owners.claim(nil, declarations(server), declarations(server), ing)
+			mergedConfig.Ingress = append(mergedConfig.Ingress, ing)
+			continue
+		}
+
+		mergedServer := m.mergeServers(baseServer, server)
+		baseDecls, mergeDecls, mergedDecls := declarations(&baseServer), declarations(server), declarations(mergedServer)
+		conflicts := owners.conflicts(host, baseDecls, mergeDecls, mergedDecls, ing)
+		if m.strict && len(conflicts) > 0 {
+			for _, conflict := range conflicts {
+				if conflict.Loser != ing {
+					conflict.Winner, conflict.Loser = conflict.Loser, conflict.Winner
+				}
+				conflict.Rejected = true
+				mergedConfig.Conflicts = append(mergedConfig.Conflicts, conflict)
+			}
+			mergedConfig.Rejected = append(mergedConfig.Rejected, ing)
+			m.log.
+				WithField("host", host).
+				WithField("ingress", ingressKey(ing)).
+				Warn("Rejecting conflicting ingress")
+			continue
 		}
-		merged = append(merged, mergedConfig)
+
+		mergedConfig.Conflicts = append(mergedConfig.Conflicts, conflicts...)
+		owners.claim(baseDecls, mergeDecls, mergedDecls, ing)
+		baseServer = *mergedServer
+		mergedConfig.Ingress = append(mergedConfig.Ingress, ing)
 	}
 
-	return
+	baseServer.Upstreams = m.getUpstreamsForServer(&baseServer)
+	mergedConfig.Server = &baseServer
+	return mergedConfig
 }
 
 func (m *mergingCollisionHandler) getUpstreamsForServer(server *config.Server) []config.Upstream {
@@ -83,17 +166,27 @@ func (m *mergingCollisionHandler) getUpstreamsForServer(server *config.Server) [
 	for _, upstream := range tmp {
 		result = append(result, upstream)
 	}
+	config.SortUpstreams(result)
 	return result
 }
 
 func (m *mergingCollisionHandler) mergeServers(base config.Server, merge *config.Server) *config.Server {
-	locationMap := map[string]config.Location{}
-	for _, location := range base.Locations {
-		locationMap[location.Path] = location
+	// locations of the merged server override locations with the same path,
+	// but keep their position to preserve the declaration order of regex locations
+	locations := append([]config.Location{}, base.Locations...)
+	locationIndex := map[string]int{}
+	for i, location := range locations {
+		locationIndex[location.Path] = i
 	}
 	for _, location := range merge.Locations {
-		locationMap[location.Path] = location
+		if i, ok := locationIndex[location.Path]; ok {
+			locations[i] = location
+			continue
+		}
+		locationIndex[location.Path] = len(locations)
+		locations = append(locations, location)
 	}
+	config.SortLocations(locations)
 
 	if merge.SSL {
 		// always enable SSL if there is at least on server configured for it
@@ -119,10 +212,12 @@ func (m *mergingCollisionHandler) mergeServers(base config.Server, merge *config
 		for _, file := range filesMap {
 			files = append(files, file)
 		}
+		config.SortFiles(files)
 		base.Files = files
 	}
 
-	if merge.HTTP2 {
+	// keep HTTP/2 enabled if any location is gRPC
+	if merge.HTTP2 || config.HasGRPCLocation(locations) {
 		base.HTTP2 = true
 	}
 	if merge.HSTS {
@@ -131,9 +226,6 @@ func (m *mergingCollisionHandler) mergeServers(base config.Server, merge *config
 		base.HSTSIncludeSubdomains = merge.HSTSIncludeSubdomains
 	}
 
-	base.Locations = []config.Location{}
-	for _, location := range locationMap {
-		base.Locations = append(base.Locations, location)
-	}
+	base.Locations = locations
 	return &base
 }
@@ -142,4 +142,31 @@ func TestMergingCollisionHandler(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Keep HTTP2 for gRPC locations", func(t *testing.T) {
+		ch := NewMergingCollisionHandler()
+		assert := assert.New(t)
+
+		grpcLocation := ingress1Location1
+		grpcLocation.Path = "/grpc"
+		grpcLocation.BackendProtocol = config.BackendProtocolGRPC
+		grpcServer := ingress1Server1
+		grpcServer.Locations = []config.Location{grpcLocation}
+
+		updated, err := ch.Resolve(MergeList{
+			IngressConfig{
+				&ingress1,
+				[]*config.Server{&grpcServer},
+			},
+			IngressConfig{
+				&ingress2,
+				[]*config.Server{&ingress2Server1},
+			},
+		})
+
+		if assert.NoError(err) && assert.Len(updated, 1) {
+			assert.Contains(updated[0].Server.Locations, grpcLocation)
+			assert.True(updated[0].Server.HTTP2, "HTTP2 should be active")
+		}
+	})
 }
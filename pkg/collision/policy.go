@@ -0,0 +1,86 @@
+package collision
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// Policy decides which ingress objects may declare a host that is claimed by multiple ingress objects
+type Policy string
+
+// Collision policies
+const (
+	// PolicyMerge merges the declarations of all ingress objects
+	PolicyMerge Policy = "merge"
+	// PolicyOldestWins uses only the declarations of the oldest ingress object of a host
+	PolicyOldestWins Policy = "oldest-wins"
+	// PolicyNamespaceOwner merges the declarations of all ingress objects in the namespace
+	// of the oldest ingress object of a host, ingress objects of other namespaces are rejected
+	PolicyNamespaceOwner Policy = "namespace-owner"
+)
+
+// PolicyAnnotation overrides the collision policy for the hosts of an ingress object.
+// Only the annotation of the oldest ingress object of a host is used,
+// so newer ingress objects cannot weaken the policy of a host.
+const PolicyAnnotation = "nginx.org/collision-policy"
+
+// Policies lists all valid collision policies
+var Policies = []Policy{PolicyMerge, PolicyOldestWins, PolicyNamespaceOwner}
+
+// ParsePolicy validates the name of a collision policy
+func ParsePolicy(name string) (Policy, error) {
+	for _, policy := range Policies {
+		if string(policy) == name {
+			return policy, nil
+		}
+	}
+
+	names := []string{}
+	for _, policy := range Policies {
+		names = append(names, string(policy))
+	}
+	return "", fmt.Errorf("unknown collision policy %q, must be one of: %s", name, strings.Join(names, ", "))
+}
+
+// NewCollisionHandler returns a CollisionHandler applying the given policy to all hosts
+// that do not override it with the PolicyAnnotation.
+// If strict is true, ingress objects that conflict with older ones are rejected instead of merged.
+func NewCollisionHandler(policy Policy, strict bool) (Handler, error) {
+	if _, err := ParsePolicy(string(policy)); err != nil {
+		return nil, err
+	}
+	return &mergingCollisionHandler{
+		log:    log.WithField("module", "CollisionHandler").WithField("policy", policy),
+		policy: policy,
+		strict: strict,
+	}, nil
+}
+
+// hostPolicy returns the policy of the host, which is the policy of the
+// oldest ingress object declaring the host or the default policy
+func hostPolicy(defaultPolicy Policy, owner *v1beta1.Ingress) (Policy, error) {
+	name, ok := owner.Annotations[PolicyAnnotation]
+	if !ok {
+		return defaultPolicy, nil
+	}
+	policy, err := ParsePolicy(strings.TrimSpace(name))
+	if err != nil {
+		return defaultPolicy, &config.IngressAnnotationError{Annotation: PolicyAnnotation, ValidationError: err}
+	}
+	return policy, nil
+}
+
+// admitted returns if the policy allows the ingress to declare the host owned by the owner
+func admitted(policy Policy, owner, ing *v1beta1.Ingress) bool {
+	switch policy {
+	case PolicyOldestWins:
+		return ing == owner
+	case PolicyNamespaceOwner:
+		return ing.Namespace == owner.Namespace
+	}
+	return true
+}
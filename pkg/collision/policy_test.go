@@ -0,0 +1,185 @@
+package collision
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func TestParsePolicy(t *testing.T) {
+	for _, policy := range Policies {
+		p, err := ParsePolicy(string(policy))
+		assert.NoError(t, err)
+		assert.Equal(t, policy, p)
+	}
+
+	_, err := ParsePolicy("newest-wins")
+	assert.EqualError(t, err, `unknown collision policy "newest-wins", must be one of: merge, oldest-wins, namespace-owner`)
+}
+
+func TestCollisionPolicies(t *testing.T) {
+	ingress := func(namespace, name string, age time.Duration, annotations map[string]string) *v1beta1.Ingress {
+		return &v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+				Annotations:       annotations,
+			},
+		}
+	}
+	server := func(path string) *config.Server {
+		return &config.Server{
+			Name:      "one.example.com",
+			Locations: []config.Location{{Path: path}},
+		}
+	}
+	owner := ingress("team-a", "owner", 3*time.Hour, nil)
+	sameNamespace := ingress("team-a", "same-namespace", 2*time.Hour, nil)
+	otherNamespace := ingress("team-b", "other-namespace", time.Hour, map[string]string{PolicyAnnotation: "merge"})
+	mergeList := func() MergeList {
+		return MergeList{
+			{Ingress: otherNamespace, Servers: []*config.Server{server("/b")}},
+			{Ingress: sameNamespace, Servers: []*config.Server{server("/a2")}},
+			{Ingress: owner, Servers: []*config.Server{server("/a1")}},
+		}
+	}
+	paths := func(server *config.Server) []string {
+		result := []string{}
+		for _, location := range server.Locations {
+			result = append(result, location.Path)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		assert := assert.New(t)
+		ch, err := NewCollisionHandler(PolicyMerge, false)
+		if !assert.NoError(err) {
+			return
+		}
+
+		merged, err := ch.Resolve(mergeList())
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Equal([]*v1beta1.Ingress{owner, sameNamespace, otherNamespace}, merged[0].Ingress)
+			assert.Equal([]string{"/a1", "/a2", "/b"}, paths(merged[0].Server))
+			assert.Empty(merged[0].Conflicts)
+		}
+	})
+
+	t.Run("oldest-wins", func(t *testing.T) {
+		assert := assert.New(t)
+		ch, err := NewCollisionHandler(PolicyOldestWins, false)
+		if !assert.NoError(err) {
+			return
+		}
+
+		merged, err := ch.Resolve(mergeList())
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Equal([]*v1beta1.Ingress{owner}, merged[0].Ingress)
+			assert.Equal([]*v1beta1.Ingress{sameNamespace, otherNamespace}, merged[0].Rejected)
+			assert.Equal([]string{"/a1"}, paths(merged[0].Server))
+			assert.Equal([]Conflict{
+				{Host: "one.example.com", Kind: ConflictHost, Name: "oldest-wins", Winner: owner, Loser: sameNamespace, Rejected: true},
+				{Host: "one.example.com", Kind: ConflictHost, Name: "oldest-wins", Winner: owner, Loser: otherNamespace, Rejected: true},
+			}, merged[0].Conflicts)
+		}
+	})
+
+	t.Run("namespace-owner", func(t *testing.T) {
+		assert := assert.New(t)
+		ch, err := NewCollisionHandler(PolicyNamespaceOwner, false)
+		if !assert.NoError(err) {
+			return
+		}
+
+		merged, err := ch.Resolve(mergeList())
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Equal([]*v1beta1.Ingress{owner, sameNamespace}, merged[0].Ingress)
+			assert.Equal([]*v1beta1.Ingress{otherNamespace}, merged[0].Rejected)
+			assert.Equal([]string{"/a1", "/a2"}, paths(merged[0].Server))
+			if assert.Len(merged[0].Conflicts, 1) {
+				assert.Equal(otherNamespace, merged[0].Conflicts[0].Loser)
+				assert.Equal(
+					`host "one.example.com" is owned by team-a/owner according to the namespace-owner collision policy, team-b/other-namespace is rejected`,
+					merged[0].Conflicts[0].Error())
+			}
+		}
+	})
+
+	t.Run("the oldest ingress overrides the policy", func(t *testing.T) {
+		assert := assert.New(t)
+		ch, err := NewCollisionHandler(PolicyMerge, false)
+		if !assert.NoError(err) {
+			return
+		}
+
+		annotatedOwner := *owner
+		annotatedOwner.Annotations = map[string]string{PolicyAnnotation: "namespace-owner"}
+		list := mergeList()
+		list[2].Ingress = &annotatedOwner
+
+		merged, err := ch.Resolve(list)
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Equal([]*v1beta1.Ingress{&annotatedOwner, sameNamespace}, merged[0].Ingress)
+		}
+	})
+
+	t.Run("invalid policies of the oldest ingress are reported", func(t *testing.T) {
+		assert := assert.New(t)
+		ch, err := NewCollisionHandler(PolicyOldestWins, false)
+		if !assert.NoError(err) {
+			return
+		}
+
+		annotatedOwner := *owner
+		annotatedOwner.Annotations = map[string]string{PolicyAnnotation: "newest-wins"}
+		list := mergeList()
+		list[2].Ingress = &annotatedOwner
+
+		merged, err := ch.Resolve(list)
+		if assert.NoError(err) && assert.Len(merged, 1) {
+			assert.Equal([]*v1beta1.Ingress{&annotatedOwner}, merged[0].Ingress)
+			if assert.Len(merged[0].Warnings, 1) {
+				assert.Equal(&annotatedOwner, merged[0].Warnings[0].Object())
+				assert.IsType(&config.IngressAnnotationError{}, merged[0].Warnings[0].WrappedError())
+			}
+		}
+	})
+
+	t.Run("ingresses created in the same second are ordered by namespace and name", func(t *testing.T) {
+		assert := assert.New(t)
+		ch, err := NewCollisionHandler(PolicyOldestWins, false)
+		if !assert.NoError(err) {
+			return
+		}
+
+		created := metav1.NewTime(time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC))
+		first := ingress("team-a", "first", 0, nil)
+		first.CreationTimestamp = created
+		second := ingress("team-b", "second", 0, nil)
+		second.CreationTimestamp = created
+
+		for _, list := range []MergeList{
+			{{Ingress: first, Servers: []*config.Server{server("/a")}}, {Ingress: second, Servers: []*config.Server{server("/b")}}},
+			{{Ingress: second, Servers: []*config.Server{server("/b")}}, {Ingress: first, Servers: []*config.Server{server("/a")}}},
+		} {
+			merged, err := ch.Resolve(list)
+			if assert.NoError(err) && assert.Len(merged, 1) {
+				assert.Equal([]*v1beta1.Ingress{first}, merged[0].Ingress)
+				assert.Equal([]string{"/a"}, paths(merged[0].Server))
+			}
+		}
+	})
+
+	t.Run("rejects unknown policies", func(t *testing.T) {
+		_, err := NewCollisionHandler(Policy("newest-wins"), false)
+		assert.Error(t, err)
+	})
+}
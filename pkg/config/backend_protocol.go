@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thetechnick/nginx-ingress/pkg/util"
+	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// BackendProtocol is the protocol used to connect to the backend services
+type BackendProtocol string
+
+// Supported backend protocols
+const (
+	BackendProtocolHTTP  BackendProtocol = "HTTP"
+	BackendProtocolHTTPS BackendProtocol = "HTTPS"
+	BackendProtocolGRPC  BackendProtocol = "GRPC"
+	BackendProtocolGRPCS BackendProtocol = "GRPCS"
+	BackendProtocolFCGI  BackendProtocol = "FCGI"
+	BackendProtocolUWSGI BackendProtocol = "UWSGI"
+)
+
+var backendProtocols = map[BackendProtocol]bool{
+	BackendProtocolHTTP:  true,
+	BackendProtocolHTTPS: true,
+	BackendProtocolGRPC:  true,
+	BackendProtocolGRPCS: true,
+	BackendProtocolFCGI:  true,
+	BackendProtocolUWSGI: true,
+}
+
+// ParseBackendProtocol parses the name of a backend protocol, the name is case insensitive
+func ParseBackendProtocol(value string) (BackendProtocol, error) {
+	protocol := BackendProtocol(strings.ToUpper(strings.TrimSpace(value)))
+	if !backendProtocols[protocol] {
+		return "", fmt.Errorf("unknown backend protocol %q, expected one of HTTP, HTTPS, GRPC, GRPCS, FCGI or UWSGI", value)
+	}
+	return protocol, nil
+}
+
+// SSL checks if the connections to the backend use TLS
+func (p BackendProtocol) SSL() bool {
+	return p == BackendProtocolHTTPS || p == BackendProtocolGRPCS
+}
+
+// GRPC checks if the backend is a gRPC service
+func (p BackendProtocol) GRPC() bool {
+	return p == BackendProtocolGRPC || p == BackendProtocolGRPCS
+}
+
+// parseBackendProtocols parses the backend protocols of the services of the Ingress.
+// nginx.org/backend-protocol-services takes precedence over the nginx.org/ssl-services,
+// nginx.org/grpc-services and nginx.org/fastcgi-services aliases, services without a protocol use the default protocol.
+// Unknown services and invalid protocols are skipped and passed to warn.
+func parseBackendProtocols(ing *extensions.Ingress, warn func(annotation string, err error)) map[string]BackendProtocol {
+	protocols := map[string]BackendProtocol{}
+	grpcServices := getGRPCServices(ing)
+	for svc := range getSSLServices(ing) {
+		if grpcServices[svc] {
+			protocols[svc] = BackendProtocolGRPCS
+		} else {
+			protocols[svc] = BackendProtocolHTTPS
+		}
+	}
+	for svc := range grpcServices {
+		if _, exists := protocols[svc]; !exists {
+			protocols[svc] = BackendProtocolGRPC
+		}
+	}
+	for svc := range getFastCGIServices(ing) {
+		protocols[svc] = BackendProtocolFCGI
+	}
+
+	annotation := "nginx.org/backend-protocol-services"
+	if value, exists := ing.Annotations[annotation]; exists {
+		values, errs := util.ParseServiceMap(value, ingressServices(ing))
+		for _, err := range errs {
+			warn(annotation, err)
+		}
+		services := make([]string, 0, len(values))
+		for svc := range values {
+			services = append(services, svc)
+		}
+		sort.Strings(services)
+		for _, svc := range services {
+			protocol, err := ParseBackendProtocol(values[svc])
+			if err != nil {
+				warn(annotation, fmt.Errorf("service %q: %v", svc, err))
+				continue
+			}
+			protocols[svc] = protocol
+		}
+	}
+	return protocols
+}
+
+func getSSLServices(ing *extensions.Ingress) (sslServices map[string]bool) {
+	sslServices = make(map[string]bool)
+	if services, exists := ing.Annotations["nginx.org/ssl-services"]; exists {
+		for _, svc := range strings.Split(services, ",") {
+			sslServices[svc] = true
+		}
+	}
+	return
+}
+
+func getGRPCServices(ing *extensions.Ingress) (grpcServices map[string]bool) {
+	grpcServices = make(map[string]bool)
+	if services, exists := ing.Annotations["nginx.org/grpc-services"]; exists {
+		for _, svc := range strings.Split(services, ",") {
+			grpcServices[svc] = true
+		}
+	}
+	return
+}
+
+func getFastCGIServices(ing *extensions.Ingress) (fastCGIServices map[string]bool) {
+	fastCGIServices = make(map[string]bool)
+	if services, exists := ing.Annotations["nginx.org/fastcgi-services"]; exists {
+		for _, svc := range strings.Split(services, ",") {
+			fastCGIServices[svc] = true
+		}
+	}
+	return
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBackendProtocol(t *testing.T) {
+	for _, value := range []string{"HTTP", "https", " GRPCS ", "FCGI", "uwsgi"} {
+		if _, err := ParseBackendProtocol(value); err != nil {
+			t.Errorf("ParseBackendProtocol(%q) returned unexpected error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "HTTP2", "https://"} {
+		if _, err := ParseBackendProtocol(value); err == nil {
+			t.Errorf("ParseBackendProtocol(%q) should have returned an error", value)
+		}
+	}
+}
+
+func TestBackendProtocols(t *testing.T) {
+	t.Run("backend-protocol-services take precedence over the aliases and the default", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{}`)
+		ing.Spec.Rules[0].HTTP.Paths[1].Backend.ServiceName = "upload-svc"
+		ing.Annotations["nginx.org/backend-protocol"] = "HTTPS"
+		ing.Annotations["nginx.org/ssl-services"] = "upload-svc"
+		ing.Annotations["nginx.org/grpc-services"] = "upload-svc"
+		ing.Annotations["nginx.org/backend-protocol-services"] = "svc1=FCGI"
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if !assert.NoError(err) || !assert.NoError(warning) {
+			return
+		}
+		assert.Equal(BackendProtocolGRPCS, ingCfg.backendProtocol("upload-svc"))
+		assert.Equal(BackendProtocolFCGI, ingCfg.backendProtocol("svc1"))
+		assert.Equal(BackendProtocolHTTPS, ingCfg.backendProtocol("other-svc"))
+	})
+
+	t.Run("skip invalid protocols", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{}`)
+		ing.Annotations["nginx.org/backend-protocol"] = "HTTP3"
+		ing.Annotations["nginx.org/backend-protocol-services"] = "svc1=SPDY,missing-svc=HTTPS"
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if assert.NoError(err) && assert.Error(warning) {
+			assert.Contains(warning.Error(), `"nginx.org/backend-protocol": unknown backend protocol "HTTP3"`)
+			assert.Contains(warning.Error(), `service "svc1": unknown backend protocol "SPDY"`)
+			assert.Contains(warning.Error(), `unknown service "missing-svc"`)
+			assert.Equal(BackendProtocolHTTP, ingCfg.backendProtocol("svc1"))
+		}
+	})
+
+	t.Run("fastcgi settings only apply to FastCGI services", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{}`)
+		ing.Spec.Rules[0].HTTP.Paths[1].Backend.ServiceName = "php-svc"
+		ing.Annotations["nginx.org/fastcgi-services"] = "php-svc"
+		ing.Annotations["nginx.org/fastcgi-index"] = "index.php"
+		ing.Annotations["nginx.org/fastcgi-params-configmap"] = "php-params"
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if !assert.NoError(err) || !assert.NoError(warning) {
+			return
+		}
+		assert.Equal("php-params", ingCfg.FastCGIParamsConfigMap)
+		servers, _, err := NewServerConfigParser().Parse(*NewDefaultConfig(), *ingCfg, nil, map[string][]string{})
+		if !assert.NoError(err) || !assert.Len(servers, 1) || !assert.Len(servers[0].Locations, 2) {
+			return
+		}
+		php, root := servers[0].Locations[0], servers[0].Locations[1]
+		assert.True(php.FastCGI())
+		assert.Equal("index.php", php.FastCGIIndex)
+		assert.False(root.FastCGI())
+		assert.Empty(root.FastCGIIndex)
+	})
+
+	t.Run("invalid fastcgi-index", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{}`)
+		ing.Annotations["nginx.org/fastcgi-index"] = "index.php; deny all"
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if assert.NoError(err) && assert.Error(warning) {
+			assert.Contains(warning.Error(), `Skipping annotation "nginx.org/fastcgi-index": invalid file name`)
+			assert.Nil(ingCfg.FastCGIIndex)
+		}
+	})
+
+	t.Run("proxy SSL settings only apply to SSL services", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{}`)
+		ing.Spec.Rules[0].HTTP.Paths[1].Backend.ServiceName = "upload-svc"
+		ing.Annotations["nginx.org/ssl-services"] = "upload-svc"
+		ing.Annotations["nginx.org/proxy-ssl-secret"] = "backend-ca"
+		ing.Annotations["nginx.org/proxy-ssl-verify"] = "true"
+		ing.Annotations["nginx.org/proxy-ssl-name"] = "upload.internal"
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if !assert.NoError(err) || !assert.NoError(warning) {
+			return
+		}
+		servers, _, err := NewServerConfigParser().Parse(*NewDefaultConfig(), *ingCfg, nil, map[string][]string{})
+		if !assert.NoError(err) || !assert.Len(servers, 1) || !assert.Len(servers[0].Locations, 2) {
+			return
+		}
+		upload, root := servers[0].Locations[0], servers[0].Locations[1]
+		assert.True(upload.SSL())
+		assert.True(upload.ProxySSLVerify)
+		assert.Equal("upload.internal", upload.ProxySSLName)
+		assert.False(root.SSL())
+		assert.False(root.ProxySSLVerify)
+		assert.Empty(root.ProxySSLName)
+	})
+
+	t.Run("proxy-ssl-verify needs proxy-ssl-secret", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{}`)
+		ing.Annotations["nginx.org/proxy-ssl-verify"] = "true"
+		ing.Annotations["nginx.org/proxy-ssl-name"] = "$host"
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if assert.NoError(err) && assert.Error(warning) {
+			assert.Contains(warning.Error(), "only valid with 'nginx.org/proxy-ssl-secret' annotation")
+			assert.Contains(warning.Error(), `invalid server name "$host"`)
+			assert.Nil(ingCfg.ProxySSLVerify)
+			assert.Nil(ingCfg.ProxySSLName)
+		}
+	})
+}
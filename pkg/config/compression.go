@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/thetechnick/nginx-ingress/pkg/util"
+)
+
+// compression settings, the annotations use the "nginx.org/" prefix
+const (
+	useGzipKey       = "use-gzip"
+	gzipLevelKey     = "gzip-level"
+	gzipMinLengthKey = "gzip-min-length"
+	gzipTypesKey     = "gzip-types"
+	useBrotliKey     = "use-brotli"
+)
+
+// CompressionConfig contains the compression settings of an Ingress,
+// nil values are not set and use the value of the global config
+type CompressionConfig struct {
+	UseGzip       *bool
+	GzipLevel     *int64
+	GzipMinLength *string
+	GzipTypes     []string
+	UseBrotli     *bool
+}
+
+// validateGzipLevel checks that the level is a valid gzip compression level
+func validateGzipLevel(level int64) error {
+	if level < 1 || level > 9 {
+		return fmt.Errorf("invalid gzip level %d, expected a number from 1 to 9", level)
+	}
+	return nil
+}
+
+// parseCompressionConfig parses the compression settings from the map, the keys are prefixed with prefix.
+// Invalid values are skipped and passed to warn.
+func parseCompressionConfig(m map[string]string, prefix string, warn func(key string, err error)) CompressionConfig {
+	cfg := CompressionConfig{}
+	if useGzip, exists, err := util.GetMapKeyAsBool(m, prefix+useGzipKey); exists {
+		if err != nil {
+			warn(prefix+useGzipKey, err)
+		} else {
+			cfg.UseGzip = &useGzip
+		}
+	}
+	if gzipLevel, exists, err := util.GetMapKeyAsInt(m, prefix+gzipLevelKey); exists {
+		if err == nil {
+			err = validateGzipLevel(gzipLevel)
+		}
+		if err != nil {
+			warn(prefix+gzipLevelKey, err)
+		} else {
+			cfg.GzipLevel = &gzipLevel
+		}
+	}
+	if gzipMinLength, exists, err := util.GetMapKeyAsSize(m, prefix+gzipMinLengthKey); exists {
+		if err != nil {
+			warn(prefix+gzipMinLengthKey, err)
+		} else {
+			cfg.GzipMinLength = &gzipMinLength
+		}
+	}
+	if gzipTypes, exists, err := util.GetMapKeyAsMIMETypes(m, prefix+gzipTypesKey); exists {
+		if err != nil {
+			warn(prefix+gzipTypesKey, err)
+		} else {
+			cfg.GzipTypes = gzipTypes
+		}
+	}
+	if useBrotli, exists, err := util.GetMapKeyAsBool(m, prefix+useBrotliKey); exists {
+		if err != nil {
+			warn(prefix+useBrotliKey, err)
+		} else {
+			cfg.UseBrotli = &useBrotli
+		}
+	}
+	return cfg
+}
+
+// apply sets the compression settings in the global config
+func (c CompressionConfig) apply(cfg *GlobalConfig) {
+	cfg.UseGzip = defaultBool(cfg.UseGzip, c.UseGzip)
+	cfg.GzipLevel = defaultInt64(cfg.GzipLevel, c.GzipLevel)
+	cfg.GzipMinLength = defaultString(cfg.GzipMinLength, c.GzipMinLength)
+	cfg.GzipTypes = defaultStringSlice(cfg.GzipTypes, c.GzipTypes)
+	cfg.UseBrotli = defaultBool(cfg.UseBrotli, c.UseBrotli)
+}
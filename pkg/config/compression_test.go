@@ -0,0 +1,140 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func TestParseCompressionConfig(t *testing.T) {
+	t.Run("should parse valid settings", func(t *testing.T) {
+		assert := assert.New(t)
+		warnings := map[string]error{}
+
+		cfg := parseCompressionConfig(map[string]string{
+			"nginx.org/use-gzip":        "false",
+			"nginx.org/gzip-level":      "9",
+			"nginx.org/gzip-min-length": "1k",
+			"nginx.org/gzip-types":      "application/json, text/*",
+			"nginx.org/use-brotli":      "true",
+		}, "nginx.org/", func(key string, err error) {
+			warnings[key] = err
+		})
+
+		assert.Empty(warnings)
+		if assert.NotNil(cfg.UseGzip) {
+			assert.False(*cfg.UseGzip)
+		}
+		if assert.NotNil(cfg.GzipLevel) {
+			assert.Equal(int64(9), *cfg.GzipLevel)
+		}
+		if assert.NotNil(cfg.GzipMinLength) {
+			assert.Equal("1k", *cfg.GzipMinLength)
+		}
+		assert.Equal([]string{"application/json", "text/*"}, cfg.GzipTypes)
+		if assert.NotNil(cfg.UseBrotli) {
+			assert.True(*cfg.UseBrotli)
+		}
+	})
+
+	t.Run("should skip invalid settings", func(t *testing.T) {
+		assert := assert.New(t)
+		warnings := map[string]error{}
+
+		cfg := parseCompressionConfig(map[string]string{
+			"gzip-level":      "10",
+			"gzip-min-length": "1 k",
+			"gzip-types":      "application/json; gzip off",
+			"use-brotli":      "yes",
+		}, "", func(key string, err error) {
+			warnings[key] = err
+		})
+
+		assert.Len(warnings, 4)
+		assert.Equal(CompressionConfig{}, cfg)
+	})
+}
+
+func TestCreateServerConfigCompression(t *testing.T) {
+	gCfg := NewDefaultConfig()
+	gCfg.GzipTypes = []string{"text/css"}
+
+	t.Run("should use the global settings", func(t *testing.T) {
+		assert := assert.New(t)
+		server := CreateServerConfig(gCfg, &IngressConfig{})
+
+		assert.True(server.Gzip)
+		assert.Equal(int64(1), server.GzipLevel)
+		assert.Equal("20", server.GzipMinLength)
+		assert.Equal([]string{"text/css"}, server.GzipTypes)
+		assert.False(server.Brotli)
+		assert.False(server.BrotliModule)
+	})
+
+	t.Run("should use the settings of the ingress", func(t *testing.T) {
+		assert := assert.New(t)
+		useGzip := false
+		useBrotli := true
+		server := CreateServerConfig(gCfg, &IngressConfig{
+			Compression: CompressionConfig{
+				UseGzip:   &useGzip,
+				GzipTypes: []string{"application/json"},
+				UseBrotli: &useBrotli,
+			},
+		})
+
+		assert.False(server.Gzip)
+		assert.Equal([]string{"application/json"}, server.GzipTypes)
+		assert.False(server.Brotli, "brotli requires the module to be enabled globally")
+		assert.False(server.BrotliModule)
+	})
+
+	t.Run("should skip brotli if it is not enabled globally", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := &v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "ing1",
+				Namespace:   "default",
+				Annotations: map[string]string{"nginx.org/use-brotli": "true"},
+			},
+			Spec: v1beta1.IngressSpec{
+				Backend: &v1beta1.IngressBackend{ServiceName: "svc1", ServicePort: intstr.FromInt(80)},
+			},
+		}
+		ingCfg, _, err := NewIngressConfigParser().Parse(ing)
+		if !assert.NoError(err) {
+			return
+		}
+
+		_, warning, err := NewServerConfigParser().Parse(*gCfg, *ingCfg, nil, map[string][]string{})
+		if assert.NoError(err) && assert.Error(warning) {
+			assert.Contains(warning.Error(), `"nginx.org/use-brotli"`)
+		}
+	})
+
+	t.Run("should use the brotli setting of the ingress if it is enabled globally", func(t *testing.T) {
+		assert := assert.New(t)
+		gCfg := NewDefaultConfig()
+		gCfg.UseBrotli = true
+		server := CreateServerConfig(gCfg, &IngressConfig{})
+
+		assert.True(server.Brotli)
+		assert.True(server.BrotliModule)
+	})
+
+	t.Run("should disable brotli if the module is enabled globally", func(t *testing.T) {
+		assert := assert.New(t)
+		gCfg := NewDefaultConfig()
+		gCfg.UseBrotli = true
+		useBrotli := false
+		server := CreateServerConfig(gCfg, &IngressConfig{
+			Compression: CompressionConfig{UseBrotli: &useBrotli},
+		})
+
+		assert.False(server.Brotli)
+		assert.True(server.BrotliModule)
+	})
+}
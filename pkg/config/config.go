@@ -44,9 +44,12 @@ type Server struct {
 	Files             []*pb.File
 
 	// settings/annotations
-	ServerSnippets        []string
-	ServerTokens          bool
-	HTTP2                 bool
+	ServerSnippets []string
+	ServerTokens   bool
+	HTTP2          bool
+	// H2C enables HTTP/2 without TLS on port 80 for all servers, as nginx shares the
+	// listen options of a port, only clients with prior knowledge can connect
+	H2C                   bool
 	RedirectToHTTPS       bool
 	ProxyProtocol         bool
 	HSTS                  bool
@@ -59,6 +62,15 @@ type Server struct {
 	RealIPHeader    string
 	SetRealIPFrom   []string
 	RealIPRecursive bool
+
+	// http://nginx.org/en/docs/http/ngx_http_gzip_module.html,
+	// the brotli directives are only valid if the global config enables the ngx_brotli module
+	Gzip          bool
+	GzipLevel     int64
+	GzipMinLength string
+	GzipTypes     []string
+	Brotli        bool
+	BrotliModule  bool
 }
 
 // CreateServerConfig creates a new server config from the given params
@@ -66,6 +78,7 @@ func CreateServerConfig(gCfg *GlobalConfig, ingCfg *IngressConfig) *Server {
 	return &Server{
 		ServerTokens:          defaultBool(gCfg.ServerTokens, ingCfg.ServerTokens),
 		HTTP2:                 defaultBool(gCfg.HTTP2, ingCfg.HTTP2),
+		H2C:                   gCfg.H2C,
 		RedirectToHTTPS:       defaultBool(gCfg.RedirectToHTTPS, ingCfg.RedirectToHTTPS),
 		ProxyProtocol:         defaultBool(gCfg.ProxyProtocol, ingCfg.ProxyProtocol),
 		HSTS:                  defaultBool(gCfg.HSTS, ingCfg.HSTS),
@@ -77,6 +90,12 @@ func CreateServerConfig(gCfg *GlobalConfig, ingCfg *IngressConfig) *Server {
 		ProxyHideHeaders:      defaultStringSlice(gCfg.ProxyHideHeaders, ingCfg.ProxyHideHeaders),
 		ProxyPassHeaders:      defaultStringSlice(gCfg.ProxyPassHeaders, ingCfg.ProxyPassHeaders),
 		ServerSnippets:        defaultStringSlice(gCfg.ServerSnippets, ingCfg.ServerSnippets),
+		Gzip:                  defaultBool(gCfg.UseGzip, ingCfg.Compression.UseGzip),
+		GzipLevel:             defaultInt64(gCfg.GzipLevel, ingCfg.Compression.GzipLevel),
+		GzipMinLength:         defaultString(gCfg.GzipMinLength, ingCfg.Compression.GzipMinLength),
+		GzipTypes:             defaultStringSlice(gCfg.GzipTypes, ingCfg.Compression.GzipTypes),
+		Brotli:                gCfg.UseBrotli && defaultBool(true, ingCfg.Compression.UseBrotli),
+		BrotliModule:          gCfg.UseBrotli,
 		Files:                 []*pb.File{},
 	}
 }
@@ -93,7 +112,7 @@ type Location struct {
 	ClientMaxBodySize    string
 	Websocket            bool
 	Rewrite              string
-	SSL                  bool
+	BackendProtocol      BackendProtocol
 	ProxyBuffering       bool
 	ProxyBuffers         string
 	ProxyBufferSize      string
@@ -101,6 +120,53 @@ type Location struct {
 
 	// http://nginx.org/en/docs/http/ngx_http_auth_basic_module.html
 	BasicAuth, BasicAuthUserFile string
+
+	// http://nginx.org/en/docs/http/ngx_http_proxy_module.html#proxy_ssl_verify,
+	// the files are set from the nginx.org/proxy-ssl-secret by the configurator
+	ProxySSLVerify             bool
+	ProxySSLName               string
+	ProxySSLTrustedCertificate string
+	ProxySSLCertificate        string
+
+	// http://nginx.org/en/docs/http/ngx_http_fastcgi_module.html,
+	// the params are set from the nginx.org/fastcgi-params-configmap by the configurator
+	FastCGIIndex  string
+	FastCGIParams map[string]string
+}
+
+// HTTP checks if the backend is proxied with the HTTP proxy module, using HTTP or HTTPS
+func (l Location) HTTP() bool {
+	return l.BackendProtocol == BackendProtocolHTTP || l.BackendProtocol == BackendProtocolHTTPS || l.BackendProtocol == ""
+}
+
+// SSL checks if the connections to the backend use TLS
+func (l Location) SSL() bool {
+	return l.BackendProtocol.SSL()
+}
+
+// GRPC checks if the backend is a gRPC service
+func (l Location) GRPC() bool {
+	return l.BackendProtocol.GRPC()
+}
+
+// FastCGI checks if the backend is a FastCGI server
+func (l Location) FastCGI() bool {
+	return l.BackendProtocol == BackendProtocolFCGI
+}
+
+// UWSGI checks if the backend is a uwsgi server
+func (l Location) UWSGI() bool {
+	return l.BackendProtocol == BackendProtocolUWSGI
+}
+
+// HasGRPCLocation checks if one of the locations proxies to a gRPC service
+func HasGRPCLocation(locations []Location) bool {
+	for _, location := range locations {
+		if location.GRPC() {
+			return true
+		}
+	}
+	return false
 }
 
 // IngressEx holds an Ingress along with Endpoints of the services
@@ -111,32 +177,45 @@ type IngressEx struct {
 	Secrets   map[string]*api_v1.Secret // indexed by secret name
 }
 
-// CreateLocation creates a new location from the given params
+// CreateLocation creates a new location from the given params,
+// the settings of locCfg override the settings of svcCfg,
+// which override the settings of the ingress, which override the global config
 func CreateLocation(
 	path string,
 	upstream Upstream,
 	gCfg *GlobalConfig,
 	ingCfg *IngressConfig,
+	svcCfg *LocationConfig,
+	locCfg *LocationConfig,
 	websocket bool,
 	rewrite string,
-	ssl bool,
+	protocol BackendProtocol,
 ) Location {
+	// precedence: global config, ingress annotations, service overrides, location overrides
+	cfg := ingCfg.LocationConfig.merge(svcCfg).merge(locCfg)
 	loc := Location{
-		Path:      path,
-		Upstream:  upstream,
-		Websocket: websocket,
-		Rewrite:   rewrite,
-		SSL:       ssl,
-
-		ProxyConnectTimeout:  defaultString(gCfg.ProxyConnectTimeout, ingCfg.ProxyConnectTimeout),
-		ProxyReadTimeout:     defaultString(gCfg.ProxyReadTimeout, ingCfg.ProxyReadTimeout),
-		ClientMaxBodySize:    defaultString(gCfg.ClientMaxBodySize, ingCfg.ClientMaxBodySize),
-		ProxyBuffering:       defaultBool(gCfg.ProxyBuffering, ingCfg.ProxyBuffering),
-		ProxyBuffers:         defaultString(gCfg.ProxyBuffers, ingCfg.ProxyBuffers),
-		ProxyBufferSize:      defaultString(gCfg.ProxyBufferSize, ingCfg.ProxyBufferSize),
-		ProxyMaxTempFileSize: defaultString(gCfg.ProxyMaxTempFileSize, ingCfg.ProxyMaxTempFileSize),
+		Path:            path,
+		Upstream:        upstream,
+		Websocket:       websocket,
+		Rewrite:         rewrite,
+		BackendProtocol: protocol,
+
+		ProxyConnectTimeout:  defaultString(gCfg.ProxyConnectTimeout, cfg.ProxyConnectTimeout),
+		ProxyReadTimeout:     defaultString(gCfg.ProxyReadTimeout, cfg.ProxyReadTimeout),
+		ClientMaxBodySize:    defaultString(gCfg.ClientMaxBodySize, cfg.ClientMaxBodySize),
+		ProxyBuffering:       defaultBool(gCfg.ProxyBuffering, cfg.ProxyBuffering),
+		ProxyBuffers:         defaultString(gCfg.ProxyBuffers, cfg.ProxyBuffers),
+		ProxyBufferSize:      defaultString(gCfg.ProxyBufferSize, cfg.ProxyBufferSize),
+		ProxyMaxTempFileSize: defaultString(gCfg.ProxyMaxTempFileSize, cfg.ProxyMaxTempFileSize),
 		LocationSnippets:     defaultStringSlice(gCfg.LocationSnippets, ingCfg.LocationSnippets),
 	}
+	if protocol.SSL() {
+		loc.ProxySSLVerify = defaultBool(false, ingCfg.ProxySSLVerify)
+		loc.ProxySSLName = defaultString("", ingCfg.ProxySSLName)
+	}
+	if loc.FastCGI() {
+		loc.FastCGIIndex = defaultString("", ingCfg.FastCGIIndex)
+	}
 
 	return loc
 }
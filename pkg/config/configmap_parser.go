@@ -36,39 +36,15 @@ func (p *configMapParser) Parse(cfgm *api_v1.ConfigMap) (*GlobalConfig, error) {
 	cfg := NewDefaultConfig()
 
 	// No validator/parser
-	if clientMaxBodySize, exists := cfgm.Data["client-max-body-size"]; exists {
-		cfg.ClientMaxBodySize = clientMaxBodySize
+	if mainTemplate, exists := cfgm.Data["main-template"]; exists {
+		cfg.MainTemplate = mainTemplate
 	}
-	if serverNamesHashBucketSize, exists := cfgm.Data["server-names-hash-bucket-size"]; exists {
-		cfg.MainServerNamesHashBucketSize = serverNamesHashBucketSize
-	}
-	if serverNamesHashMaxSize, exists := cfgm.Data["server-names-hash-max-size"]; exists {
-		cfg.MainServerNamesHashMaxSize = serverNamesHashMaxSize
-	}
-	if proxyConnectTimeout, exists := cfgm.Data["proxy-connect-timeout"]; exists {
-		cfg.ProxyConnectTimeout = proxyConnectTimeout
-	}
-	if proxyReadTimeout, exists := cfgm.Data["proxy-read-timeout"]; exists {
-		cfg.ProxyReadTimeout = proxyReadTimeout
-	}
-	if realIPHeader, exists := cfgm.Data["real-ip-header"]; exists {
-		cfg.RealIPHeader = realIPHeader
-	}
-	if sslProtocols, exists := cfgm.Data["ssl-protocols"]; exists {
-		cfg.MainServerSSLProtocols = sslProtocols
+	if ingressTemplate, exists := cfgm.Data["ingress-template"]; exists {
+		cfg.IngressTemplate = ingressTemplate
 	}
 	if sslCiphers, exists := cfgm.Data["ssl-ciphers"]; exists {
 		cfg.MainServerSSLCiphers = strings.Trim(sslCiphers, "\n")
 	}
-	if proxyBuffers, exists := cfgm.Data["proxy-buffers"]; exists {
-		cfg.ProxyBuffers = proxyBuffers
-	}
-	if proxyBufferSize, exists := cfgm.Data["proxy-buffer-size"]; exists {
-		cfg.ProxyBufferSize = proxyBufferSize
-	}
-	if proxyMaxTempFileSize, exists := cfgm.Data["proxy-max-temp-file-size"]; exists {
-		cfg.ProxyMaxTempFileSize = proxyMaxTempFileSize
-	}
 	if mainHTTPSnippets, exists := util.GetMapKeyAsStringSlice(cfgm.Data, "http-snippets", cfgm, "\n"); exists {
 		cfg.MainHTTPSnippets = mainHTTPSnippets
 	}
@@ -78,23 +54,17 @@ func (p *configMapParser) Parse(cfgm *api_v1.ConfigMap) (*GlobalConfig, error) {
 	if serverSnippets, exists := util.GetMapKeyAsStringSlice(cfgm.Data, "server-snippets", cfgm, "\n"); exists {
 		cfg.ServerSnippets = serverSnippets
 	}
-	if proxyHideHeaders, exists := util.GetMapKeyAsStringSlice(cfgm.Data, "proxy-hide-headers", cfgm, ","); exists {
-		cfg.ProxyHideHeaders = proxyHideHeaders
-	}
-	if proxyPassHeaders, exists := util.GetMapKeyAsStringSlice(cfgm.Data, "proxy-pass-headers", cfgm, ","); exists {
-		cfg.ProxyPassHeaders = proxyPassHeaders
-	}
 	if sslDHParamFile, exists := cfgm.Data["ssl-dhparam-file"]; exists {
 		cfg.MainServerSSLDHParamFile = strings.Trim(sslDHParamFile, "\n")
 	}
 	if logFormat, exists := cfgm.Data["log-format"]; exists {
 		cfg.MainLogFormat = logFormat
 	}
-	if workerShutdownTimeout, exists := cfgm.Data["worker-shutdown-timeout"]; exists {
-		cfg.MainWorkerShutdownTimeout = workerShutdownTimeout
+	if allowlist, exists := util.GetMapKeyAsStringSlice(cfgm.Data, "snippet-directives-allowlist", cfgm, ","); exists {
+		cfg.SnippetDirectivesAllowlist = directivePatterns(allowlist)
 	}
-	if setRealIPFrom, exists := util.GetMapKeyAsStringSlice(cfgm.Data, "set-real-ip-from", cfgm, ","); exists {
-		cfg.SetRealIPFrom = setRealIPFrom
+	if denylist, exists := util.GetMapKeyAsStringSlice(cfgm.Data, "snippet-directives-denylist", cfgm, ","); exists {
+		cfg.SnippetDirectivesDenylist = directivePatterns(denylist)
 	}
 
 	//
@@ -114,6 +84,13 @@ func (p *configMapParser) Parse(cfgm *api_v1.ConfigMap) (*GlobalConfig, error) {
 			cfg.HTTP2 = HTTP2
 		}
 	}
+	if h2c, exists, err := util.GetMapKeyAsBool(cfgm.Data, "h2c"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"h2c", err})
+		} else {
+			cfg.H2C = h2c
+		}
+	}
 	if redirectToHTTPS, exists, err := util.GetMapKeyAsBool(cfgm.Data, "redirect-to-https"); exists {
 		if err != nil {
 			errs = append(errs, &ConfigMapKeyError{"redirect-to-https", err})
@@ -161,6 +138,41 @@ func (p *configMapParser) Parse(cfgm *api_v1.ConfigMap) (*GlobalConfig, error) {
 		}
 	}
 
+	if realIPHeader, exists := cfgm.Data["real-ip-header"]; exists {
+		if v, err := util.ParseHeaderName(realIPHeader); err != nil {
+			errs = append(errs, &ConfigMapKeyError{"real-ip-header", err})
+		} else {
+			cfg.RealIPHeader = v
+		}
+	}
+	if setRealIPFrom, exists, err := util.GetMapKeyAsAddresses(cfgm.Data, "set-real-ip-from"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"set-real-ip-from", err})
+		} else {
+			cfg.SetRealIPFrom = setRealIPFrom
+		}
+	}
+	if proxyHideHeaders, exists, err := util.GetMapKeyAsHeaderNames(cfgm.Data, "proxy-hide-headers"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"proxy-hide-headers", err})
+		} else {
+			cfg.ProxyHideHeaders = proxyHideHeaders
+		}
+	}
+	if proxyPassHeaders, exists, err := util.GetMapKeyAsHeaderNames(cfgm.Data, "proxy-pass-headers"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"proxy-pass-headers", err})
+		} else {
+			cfg.ProxyPassHeaders = proxyPassHeaders
+		}
+	}
+	if sslProtocols, exists := cfgm.Data["ssl-protocols"]; exists {
+		if v, err := parseSSLProtocols(strings.Fields(sslProtocols)); err != nil {
+			errs = append(errs, &ConfigMapKeyError{"ssl-protocols", err})
+		} else {
+			cfg.MainServerSSLProtocols = v
+		}
+	}
 	if realIPRecursive, exists, err := util.GetMapKeyAsBool(cfgm.Data, "real-ip-recursive"); exists {
 		if err != nil {
 			errs = append(errs, &ConfigMapKeyError{"real-ip-recursive", err})
@@ -185,6 +197,138 @@ func (p *configMapParser) Parse(cfgm *api_v1.ConfigMap) (*GlobalConfig, error) {
 		}
 	}
 
+	parseCompressionConfig(cfgm.Data, "", func(key string, err error) {
+		errs = append(errs, &ConfigMapKeyError{key, err})
+	}).apply(cfg)
+
+	if clientMaxBodySize, exists, err := util.GetMapKeyAsOffset(cfgm.Data, "client-max-body-size"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"client-max-body-size", err})
+		} else {
+			cfg.ClientMaxBodySize = clientMaxBodySize
+		}
+	}
+	if serverNamesHashBucketSize, exists, err := util.GetMapKeyAsNumber(cfgm.Data, "server-names-hash-bucket-size"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"server-names-hash-bucket-size", err})
+		} else {
+			cfg.MainServerNamesHashBucketSize = serverNamesHashBucketSize
+		}
+	}
+	if serverNamesHashMaxSize, exists, err := util.GetMapKeyAsNumber(cfgm.Data, "server-names-hash-max-size"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"server-names-hash-max-size", err})
+		} else {
+			cfg.MainServerNamesHashMaxSize = serverNamesHashMaxSize
+		}
+	}
+	if proxyConnectTimeout, exists, err := util.GetMapKeyAsTime(cfgm.Data, "proxy-connect-timeout"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"proxy-connect-timeout", err})
+		} else {
+			cfg.ProxyConnectTimeout = proxyConnectTimeout
+		}
+	}
+	if proxyReadTimeout, exists, err := util.GetMapKeyAsTime(cfgm.Data, "proxy-read-timeout"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"proxy-read-timeout", err})
+		} else {
+			cfg.ProxyReadTimeout = proxyReadTimeout
+		}
+	}
+	if proxyBuffers, exists, err := util.GetMapKeyAsBufferSpec(cfgm.Data, "proxy-buffers"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"proxy-buffers", err})
+		} else {
+			cfg.ProxyBuffers = proxyBuffers
+		}
+	}
+	if proxyBufferSize, exists, err := util.GetMapKeyAsSize(cfgm.Data, "proxy-buffer-size"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"proxy-buffer-size", err})
+		} else {
+			cfg.ProxyBufferSize = proxyBufferSize
+		}
+	}
+	if proxyMaxTempFileSize, exists, err := util.GetMapKeyAsSize(cfgm.Data, "proxy-max-temp-file-size"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"proxy-max-temp-file-size", err})
+		} else {
+			cfg.ProxyMaxTempFileSize = proxyMaxTempFileSize
+		}
+	}
+	if workerShutdownTimeout, exists, err := util.GetMapKeyAsTime(cfgm.Data, "worker-shutdown-timeout"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"worker-shutdown-timeout", err})
+		} else {
+			cfg.MainWorkerShutdownTimeout = workerShutdownTimeout
+		}
+	}
+
+	if workerProcesses, exists, err := util.GetMapKeyAsWorkerProcesses(cfgm.Data, "worker-processes"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"worker-processes", err})
+		} else {
+			cfg.MainWorkerProcesses = workerProcesses
+		}
+	}
+	if workerConnections, exists, err := util.GetMapKeyAsNumber(cfgm.Data, "worker-connections"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"worker-connections", err})
+		} else {
+			cfg.MainWorkerConnections = workerConnections
+		}
+	}
+	if workerRLimitNofile, exists, err := util.GetMapKeyAsNumber(cfgm.Data, "worker-rlimit-nofile"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"worker-rlimit-nofile", err})
+		} else {
+			cfg.MainWorkerRLimitNofile = workerRLimitNofile
+		}
+	}
+	if workerCPUAffinity, exists, err := util.GetMapKeyAsCPUAffinity(cfgm.Data, "worker-cpu-affinity"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"worker-cpu-affinity", err})
+		} else {
+			cfg.MainWorkerCPUAffinity = workerCPUAffinity
+		}
+	}
+	if errorLogLevel, exists, err := util.GetMapKeyAsLogLevel(cfgm.Data, "error-log-level"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"error-log-level", err})
+		} else {
+			cfg.MainErrorLogLevel = errorLogLevel
+		}
+	}
+	if keepaliveTimeout, exists, err := util.GetMapKeyAsTime(cfgm.Data, "keepalive-timeout"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"keepalive-timeout", err})
+		} else {
+			cfg.MainKeepaliveTimeout = keepaliveTimeout
+		}
+	}
+	if keepaliveRequests, exists, err := util.GetMapKeyAsNumber(cfgm.Data, "keepalive-requests"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"keepalive-requests", err})
+		} else {
+			cfg.MainKeepaliveRequests = keepaliveRequests
+		}
+	}
+	if accessLogPath, exists := cfgm.Data["access-log-path"]; exists {
+		if v, err := parseAccessLogPath(accessLogPath); err != nil {
+			errs = append(errs, &ConfigMapKeyError{"access-log-path", err})
+		} else {
+			cfg.MainAccessLogPath = v
+		}
+	}
+	if accessLogOff, exists, err := util.GetMapKeyAsBool(cfgm.Data, "access-log-off"); exists {
+		if err != nil {
+			errs = append(errs, &ConfigMapKeyError{"access-log-off", err})
+		} else {
+			cfg.MainAccessLogOff = accessLogOff
+		}
+	}
+
 	if len(errs) > 0 {
 		return cfg, errors.WrapInObjectContext(ValidationError(errs), cfgm)
 	}
@@ -62,6 +62,7 @@ func TestConfigMapParser(t *testing.T) {
 			Data: map[string]string{
 				"server-tokens":             "not a bool",
 				"http2":                     "not a bool",
+				"h2c":                       "not a bool",
 				"redirect-to-https":         "not a bool",
 				"hsts":                      "not a bool",
 				"hsts-max-age":              "not a int",
@@ -77,7 +78,7 @@ func TestConfigMapParser(t *testing.T) {
 			cerr := err.(errors.ErrObjectContext)
 			if assert.IsType(ValidationError{}, cerr.WrappedError()) {
 				verr := cerr.WrappedError().(ValidationError)
-				assert.Len(verr, 11)
+				assert.Len(verr, 12)
 			}
 		}
 
@@ -85,4 +86,167 @@ func TestConfigMapParser(t *testing.T) {
 			assert.Equal(NewDefaultConfig(), c, "Config should be equal to default config")
 		}
 	})
+
+	t.Run("should skip invalid size, offset, time and buffer values", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, err := p.Parse(&api_v1.ConfigMap{
+			Data: map[string]string{
+				"client-max-body-size":          "1t",
+				"server-names-hash-bucket-size": "64g",
+				"server-names-hash-max-size":    "512k",
+				"proxy-connect-timeout":         "10 s",
+				"proxy-read-timeout":            "10s; return 200",
+				"proxy-buffers":                 "8",
+				"proxy-buffer-size":             "-1",
+				"proxy-max-temp-file-size":      "1g",
+				"worker-shutdown-timeout":       "forever",
+			},
+		})
+
+		if assert.NotNil(err) && assert.Implements((*errors.ErrObjectContext)(nil), err) {
+			cerr := err.(errors.ErrObjectContext)
+			if assert.IsType(ValidationError{}, cerr.WrappedError()) {
+				verr := cerr.WrappedError().(ValidationError)
+				assert.Len(verr, 9)
+			}
+		}
+
+		if assert.NotNil(c) {
+			assert.Equal(NewDefaultConfig(), c, "Config should be equal to default config")
+		}
+	})
+
+	t.Run("should only accept numbers as server names hash bucket size", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, err := p.Parse(&api_v1.ConfigMap{
+			Data: map[string]string{
+				"server-names-hash-bucket-size": "64k",
+			},
+		})
+		assert.NotNil(err)
+		if assert.NotNil(c) {
+			assert.Equal("", c.MainServerNamesHashBucketSize)
+		}
+
+		c, err = p.Parse(&api_v1.ConfigMap{
+			Data: map[string]string{
+				"server-names-hash-bucket-size": "128",
+			},
+		})
+		assert.Nil(err)
+		if assert.NotNil(c) {
+			assert.Equal("128", c.MainServerNamesHashBucketSize)
+		}
+	})
+
+	t.Run("should skip invalid header names, addresses and protocols", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, err := p.Parse(&api_v1.ConfigMap{
+			Data: map[string]string{
+				"real-ip-header":     "X-Forwarded-For; return 200",
+				"set-real-ip-from":   "10.0.0.0/8, 192.168.0.1;",
+				"proxy-hide-headers": "X-Powered-By,",
+				"proxy-pass-headers": "X-Foo, X-Bar",
+				"ssl-protocols":      "TLSv1.2; return 200",
+			},
+		})
+
+		if assert.NotNil(err) && assert.Implements((*errors.ErrObjectContext)(nil), err) {
+			cerr := err.(errors.ErrObjectContext)
+			if assert.IsType(ValidationError{}, cerr.WrappedError()) {
+				verr := cerr.WrappedError().(ValidationError)
+				assert.Len(verr, 4)
+			}
+		}
+
+		if assert.NotNil(c) {
+			assert.Equal("", c.RealIPHeader)
+			assert.Nil(c.SetRealIPFrom)
+			assert.Nil(c.ProxyHideHeaders)
+			assert.Equal([]string{"X-Foo", "X-Bar"}, c.ProxyPassHeaders)
+			assert.Equal("", c.MainServerSSLProtocols)
+		}
+	})
+
+	t.Run("should skip invalid worker and log settings", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, err := p.Parse(&api_v1.ConfigMap{
+			Data: map[string]string{
+				"worker-processes":     "max",
+				"worker-connections":   "0",
+				"worker-rlimit-nofile": "65536",
+				"worker-cpu-affinity":  "auto 0011",
+				"error-log-level":      "warning",
+				"keepalive-timeout":    "75s; daemon off",
+				"keepalive-requests":   "1000",
+				"access-log-path":      "access.log",
+				"access-log-off":       "yes",
+			},
+		})
+
+		if assert.NotNil(err) && assert.Implements((*errors.ErrObjectContext)(nil), err) {
+			cerr := err.(errors.ErrObjectContext)
+			if assert.IsType(ValidationError{}, cerr.WrappedError()) {
+				verr := cerr.WrappedError().(ValidationError)
+				assert.Len(verr, 6)
+			}
+		}
+
+		if assert.NotNil(c) {
+			assert.Equal("auto", c.MainWorkerProcesses)
+			assert.Equal("1024", c.MainWorkerConnections)
+			assert.Equal("65536", c.MainWorkerRLimitNofile)
+			assert.Equal("auto 0011", c.MainWorkerCPUAffinity)
+			assert.Equal("warn", c.MainErrorLogLevel)
+			assert.Equal("65", c.MainKeepaliveTimeout)
+			assert.Equal("1000", c.MainKeepaliveRequests)
+			assert.Equal("/var/log/nginx/access.log", c.MainAccessLogPath)
+			assert.False(c.MainAccessLogOff)
+		}
+	})
+
+	t.Run("should only accept access logs in the log directory", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, invalid := range []string{"/data/logs/access.log", "/var/log/nginx/../access.log", "/var/log/nginx/logs/access.log", "/var/log/nginx/", "/var/log/nginx/\naccess.log", "/var/log/nginx/ access.log"} {
+			c, err := p.Parse(&api_v1.ConfigMap{
+				Data: map[string]string{"access-log-path": invalid},
+			})
+			assert.NotNil(err, invalid)
+			if assert.NotNil(c) {
+				assert.Equal("/var/log/nginx/access.log", c.MainAccessLogPath)
+			}
+		}
+
+		c, err := p.Parse(&api_v1.ConfigMap{
+			Data: map[string]string{"access-log-path": "/var/log/nginx/ingress.log"},
+		})
+		assert.Nil(err)
+		if assert.NotNil(c) {
+			assert.Equal("/var/log/nginx/ingress.log", c.MainAccessLogPath)
+		}
+	})
+
+	t.Run("should accept valid size, offset, time and buffer values", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, err := p.Parse(&api_v1.ConfigMap{
+			Data: map[string]string{
+				"client-max-body-size":  "1g",
+				"proxy-connect-timeout": "1m 30s",
+				"proxy-buffers":         " 8  4k ",
+			},
+		})
+
+		assert.Nil(err)
+		if assert.NotNil(c) {
+			assert.Equal("1g", c.ClientMaxBodySize)
+			assert.Equal("1m30s", c.ProxyConnectTimeout)
+			assert.Equal("8 4k", c.ProxyBuffers)
+		}
+	})
 }
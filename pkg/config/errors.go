@@ -28,3 +28,13 @@ func (e ValidationError) Error() string {
 	}
 	return fmt.Sprintf("validation: [ %s ]", strings.Join(errString, ", "))
 }
+
+// ConfigFieldError is a config error for a field of the NginxIngressConfig object
+type ConfigFieldError struct {
+	Field           string
+	ValidationError error
+}
+
+func (e *ConfigFieldError) Error() string {
+	return fmt.Sprintf("Skipping field %q: %v", e.Field, e.ValidationError)
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+)
+
+// FastCGIParamsLabel marks ConfigMaps that contain fastcgi_param values,
+// only ConfigMaps with the label set to "true" are watched by the controller
+const FastCGIParamsLabel = "nginx.org/fastcgi-params"
+
+var fastCGIParamRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ParseFastCGIParams parses a ConfigMap mapping fastcgi_param names to values like
+// SCRIPT_FILENAME: "/var/www/html$fastcgi_script_name". The values may contain nginx variables.
+// Invalid param names are skipped and returned as warning.
+func ParseFastCGIParams(cfgm *api_v1.ConfigMap) (params map[string]string, warning error) {
+	names := make([]string, 0, len(cfgm.Data))
+	for name := range cfgm.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	warnings := []error{}
+	params = map[string]string{}
+	for _, name := range names {
+		if !fastCGIParamRegexp.MatchString(name) {
+			warnings = append(warnings, &ConfigMapKeyError{name, fmt.Errorf("invalid fastcgi_param name, expected letters, digits and underscores")})
+			continue
+		}
+		params[name] = cfgm.Data[name]
+	}
+
+	if len(warnings) > 0 {
+		warning = errors.WrapInObjectContext(ValidationError(warnings), cfgm)
+	}
+	return
+}
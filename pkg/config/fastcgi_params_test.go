@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+)
+
+func TestParseFastCGIParams(t *testing.T) {
+	assert := assert.New(t)
+	params, warning := ParseFastCGIParams(&api_v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "php-params", Namespace: "default"},
+		Data: map[string]string{
+			"SCRIPT_FILENAME": "/var/www/html$fastcgi_script_name",
+			"APP_ENV":         "production; deny all",
+			"HTTP PROXY":      "",
+			"fastcgi_param{}": "x",
+		},
+	})
+	assert.Equal(map[string]string{
+		"SCRIPT_FILENAME": "/var/www/html$fastcgi_script_name",
+		"APP_ENV":         "production; deny all",
+	}, params)
+	if assert.Error(warning) {
+		assert.Contains(warning.Error(), `"HTTP PROXY"`)
+		assert.Contains(warning.Error(), `"fastcgi_param{}"`)
+	}
+}
@@ -10,6 +10,7 @@ type GlobalConfig struct {
 	ProxyReadTimeout              string
 	ClientMaxBodySize             string
 	HTTP2                         bool
+	H2C                           bool
 	RedirectToHTTPS               bool
 	MainHTTPSnippets              []string
 	MainServerNamesHashBucketSize string
@@ -37,6 +38,37 @@ type GlobalConfig struct {
 	MainServerSSLPreferServerCiphers bool
 	MainServerSSLCiphers             string
 	MainServerSSLDHParamFile         string
+
+	// http://nginx.org/en/docs/ngx_core_module.html
+	MainWorkerProcesses    string
+	MainWorkerConnections  string
+	MainWorkerRLimitNofile string
+	MainWorkerCPUAffinity  string
+	MainErrorLogLevel      string
+
+	// http://nginx.org/en/docs/http/ngx_http_core_module.html#keepalive_timeout
+	MainKeepaliveTimeout  string
+	MainKeepaliveRequests string
+
+	// http://nginx.org/en/docs/http/ngx_http_log_module.html#access_log
+	MainAccessLogPath string
+	MainAccessLogOff  bool
+
+	// http://nginx.org/en/docs/http/ngx_http_gzip_module.html,
+	// brotli requires the ngx_brotli module and uses the gzip min length and types
+	UseGzip       bool
+	GzipLevel     int64
+	GzipMinLength string
+	GzipTypes     []string
+	UseBrotli     bool
+
+	// directives allowed/denied in snippet annotations
+	SnippetDirectivesAllowlist []string
+	SnippetDirectivesDenylist  []string
+
+	// custom templates, the embedded defaults are used if empty
+	MainTemplate    string
+	IngressTemplate string
 }
 
 // NewDefaultConfig creates a Config with default values
@@ -48,7 +80,15 @@ func NewDefaultConfig() *GlobalConfig {
 		ClientMaxBodySize:          "1m",
 		MainServerNamesHashMaxSize: "512",
 		MainWorkerShutdownTimeout:  "10s",
+		MainWorkerProcesses:        "auto",
+		MainWorkerConnections:      "1024",
+		MainErrorLogLevel:          "warn",
+		MainKeepaliveTimeout:       "65",
+		MainAccessLogPath:          "/var/log/nginx/access.log",
 		ProxyBuffering:             true,
 		HSTSMaxAge:                 2592000,
+		UseGzip:                    true,
+		GzipLevel:                  1,
+		GzipMinLength:              "20",
 	}
 }
@@ -41,20 +41,25 @@ func (p *ingParser) Parse(ing *extensions.Ingress) (ingCfg *IngressConfig, warni
 		ingCfg.LocationSnippets = locationSnippets
 	}
 
-	if proxyConnectTimeout, exists := ing.Annotations["nginx.org/proxy-connect-timeout"]; exists {
-		ingCfg.ProxyConnectTimeout = &proxyConnectTimeout
-	}
-	if proxyReadTimeout, exists := ing.Annotations["nginx.org/proxy-read-timeout"]; exists {
-		ingCfg.ProxyReadTimeout = &proxyReadTimeout
-	}
-	if proxyHideHeaders, exists := util.GetMapKeyAsStringSlice(ing.Annotations, "nginx.org/proxy-hide-headers", ing, ","); exists {
-		ingCfg.ProxyHideHeaders = proxyHideHeaders
-	}
-	if proxyPassHeaders, exists := util.GetMapKeyAsStringSlice(ing.Annotations, "nginx.org/proxy-pass-headers", ing, ","); exists {
-		ingCfg.ProxyPassHeaders = proxyPassHeaders
+	ingCfg.LocationConfig = parseLocationConfig(ing.Annotations, "nginx.org/", func(annotation string, err error) {
+		warnings = append(warnings, &IngressAnnotationError{annotation, err})
+	})
+	ingCfg.Compression = parseCompressionConfig(ing.Annotations, "nginx.org/", func(annotation string, err error) {
+		warnings = append(warnings, &IngressAnnotationError{annotation, err})
+	})
+	if proxyHideHeaders, exists, err := util.GetMapKeyAsHeaderNames(ing.Annotations, "nginx.org/proxy-hide-headers"); exists {
+		if err != nil {
+			warnings = append(warnings, &IngressAnnotationError{"nginx.org/proxy-hide-headers", err})
+		} else {
+			ingCfg.ProxyHideHeaders = proxyHideHeaders
+		}
 	}
-	if clientMaxBodySize, exists := ing.Annotations["nginx.org/client-max-body-size"]; exists {
-		ingCfg.ClientMaxBodySize = &clientMaxBodySize
+	if proxyPassHeaders, exists, err := util.GetMapKeyAsHeaderNames(ing.Annotations, "nginx.org/proxy-pass-headers"); exists {
+		if err != nil {
+			warnings = append(warnings, &IngressAnnotationError{"nginx.org/proxy-pass-headers", err})
+		} else {
+			ingCfg.ProxyPassHeaders = proxyPassHeaders
+		}
 	}
 	if HTTP2, exists, err := util.GetMapKeyAsBool(ing.Annotations, "nginx.org/http2"); exists {
 		if err != nil {
@@ -70,16 +75,9 @@ func (p *ingParser) Parse(ing *extensions.Ingress) (ingCfg *IngressConfig, warni
 			ingCfg.RedirectToHTTPS = &redirectToHTTPS
 		}
 	}
-	if proxyBuffering, exists, err := util.GetMapKeyAsBool(ing.Annotations, "nginx.org/proxy-buffering"); exists {
-		if err != nil {
-			warnings = append(warnings, &IngressAnnotationError{"nginx.org/proxy-buffering", err})
-		} else {
-			ingCfg.ProxyBuffering = &proxyBuffering
-		}
-	}
 	if basicAuth, exists := ing.Annotations["nginx.org/auth-basic"]; exists {
 		if basicAuthUserSecret, exists := ing.Annotations["nginx.org/auth-basic-user-secret"]; exists {
-			ingCfg.BasicAuth = basicAuth
+			ingCfg.BasicAuth = unquote(basicAuth)
 			ingCfg.BasicAuthUserSecret = basicAuthUserSecret
 		} else {
 			warnings = append(warnings, &IngressAnnotationError{"nginx.org/auth-basic", fmt.Errorf("only valid with 'nginx.org/auth-basic-user-secret' annotation")})
@@ -117,15 +115,6 @@ func (p *ingParser) Parse(ing *extensions.Ingress) (ingCfg *IngressConfig, warni
 		}
 	}
 
-	if proxyBuffers, exists := ing.Annotations["nginx.org/proxy-buffers"]; exists {
-		ingCfg.ProxyBuffers = &proxyBuffers
-	}
-	if proxyBufferSize, exists := ing.Annotations["nginx.org/proxy-buffer-size"]; exists {
-		ingCfg.ProxyBufferSize = &proxyBufferSize
-	}
-	if proxyMaxTempFileSize, exists := ing.Annotations["nginx.org/proxy-max-temp-file-size"]; exists {
-		ingCfg.ProxyMaxTempFileSize = &proxyMaxTempFileSize
-	}
 	if locationModifier, exists := ing.Annotations["nginx.org/location-modifier"]; exists {
 		if locationModifier != "=" &&
 			locationModifier != "~" &&
@@ -137,14 +126,62 @@ func (p *ingParser) Parse(ing *extensions.Ingress) (ingCfg *IngressConfig, warni
 		}
 	}
 
+	ingCfg.ServiceOverrides = parseServiceOverrides(ing, func(annotation string, err error) {
+		warnings = append(warnings, &IngressAnnotationError{annotation, err})
+	})
+	if overrides, exists := ing.Annotations["nginx.org/location-overrides"]; exists {
+		ingCfg.LocationOverrides = parseLocationOverrides(ing, overrides, func(err error) {
+			warnings = append(warnings, &IngressAnnotationError{"nginx.org/location-overrides", err})
+		})
+	}
+
 	ingCfg.WebsocketServices = getWebsocketServices(ing)
-	ingCfg.SSLServices = getSSLServices(ing)
+	if backendProtocol, exists := ing.Annotations["nginx.org/backend-protocol"]; exists {
+		if protocol, err := ParseBackendProtocol(backendProtocol); err != nil {
+			warnings = append(warnings, &IngressAnnotationError{"nginx.org/backend-protocol", err})
+		} else {
+			ingCfg.BackendProtocol = &protocol
+		}
+	}
+	ingCfg.BackendProtocols = parseBackendProtocols(ing, func(annotation string, err error) {
+		warnings = append(warnings, &IngressAnnotationError{annotation, err})
+	})
+	if proxySSLSecret, exists := ing.Annotations["nginx.org/proxy-ssl-secret"]; exists {
+		ingCfg.ProxySSLSecret = proxySSLSecret
+	}
+	if proxySSLVerify, exists, err := util.GetMapKeyAsBool(ing.Annotations, "nginx.org/proxy-ssl-verify"); exists {
+		if err != nil {
+			warnings = append(warnings, &IngressAnnotationError{"nginx.org/proxy-ssl-verify", err})
+		} else if proxySSLVerify && ingCfg.ProxySSLSecret == "" {
+			warnings = append(warnings, &IngressAnnotationError{"nginx.org/proxy-ssl-verify", fmt.Errorf("only valid with 'nginx.org/proxy-ssl-secret' annotation")})
+		} else {
+			ingCfg.ProxySSLVerify = &proxySSLVerify
+		}
+	}
+	if proxySSLName, exists, err := util.GetMapKeyAsServerName(ing.Annotations, "nginx.org/proxy-ssl-name"); exists {
+		if err != nil {
+			warnings = append(warnings, &IngressAnnotationError{"nginx.org/proxy-ssl-name", err})
+		} else {
+			ingCfg.ProxySSLName = &proxySSLName
+		}
+	}
 	rewrites, rerr := getRewrites(ing)
 	ingCfg.Rewrites = rewrites
 	if rerr != nil {
 		warnings = append(warnings, &IngressAnnotationError{"nginx.org/rewrites", rerr})
 	}
 
+	if fastCGIIndex, exists, err := util.GetMapKeyAsFileName(ing.Annotations, "nginx.org/fastcgi-index"); exists {
+		if err != nil {
+			warnings = append(warnings, &IngressAnnotationError{"nginx.org/fastcgi-index", err})
+		} else {
+			ingCfg.FastCGIIndex = &fastCGIIndex
+		}
+	}
+	if fastCGIParamsConfigMap, exists := ing.Annotations["nginx.org/fastcgi-params-configmap"]; exists {
+		ingCfg.FastCGIParamsConfigMap = fastCGIParamsConfigMap
+	}
+
 	if len(warnings) > 0 {
 		warning = errors.WrapInObjectContext(ValidationError(warnings), ing)
 	}
@@ -158,20 +195,23 @@ type IngressConfig struct {
 	//
 	// Annotations
 	//
-	LocationSnippets  []string
-	ServerSnippets    []string
-	ServerTokens      *bool
-	ClientMaxBodySize *string
-	HTTP2             *bool
-	RedirectToHTTPS   *bool
-	LocationModifier  *string
-
-	ProxyBuffering *bool
-	ProxyConnectTimeout,
-	ProxyReadTimeout,
-	ProxyBuffers,
-	ProxyBufferSize,
-	ProxyMaxTempFileSize *string
+	LocationSnippets []string
+	ServerSnippets   []string
+	ServerTokens     *bool
+	HTTP2            *bool
+	RedirectToHTTPS  *bool
+	LocationModifier *string
+
+	// LocationConfig contains the settings of all locations,
+	// ServiceOverrides the settings of the locations of single services indexed by service name
+	// and LocationOverrides the settings of single locations indexed by path
+	LocationConfig
+	ServiceOverrides  map[string]*LocationConfig
+	LocationOverrides map[string]*LocationConfig
+
+	// Compression overrides the compression settings of the server
+	Compression CompressionConfig
+
 	ProxyProtocol    *bool
 	ProxyHideHeaders []string
 	ProxyPassHeaders []string
@@ -189,7 +229,32 @@ type IngressConfig struct {
 
 	WebsocketServices map[string]bool
 	Rewrites          map[string]string
-	SSLServices       map[string]bool
+
+	// BackendProtocol is the protocol of all services,
+	// BackendProtocols contains the protocols of single services indexed by service name
+	BackendProtocol  *BackendProtocol
+	BackendProtocols map[string]BackendProtocol
+
+	// http://nginx.org/en/docs/http/ngx_http_proxy_module.html#proxy_ssl_verify
+	ProxySSLSecret string
+	ProxySSLVerify *bool
+	ProxySSLName   *string
+
+	// http://nginx.org/en/docs/http/ngx_http_fastcgi_module.html,
+	// the params are read from the ConfigMap by the configurator
+	FastCGIIndex           *string
+	FastCGIParamsConfigMap string
+}
+
+// backendProtocol returns the protocol of the service, HTTP is used by default
+func (c *IngressConfig) backendProtocol(service string) BackendProtocol {
+	if protocol, exists := c.BackendProtocols[service]; exists {
+		return protocol
+	}
+	if c.BackendProtocol != nil {
+		return *c.BackendProtocol
+	}
+	return BackendProtocolHTTP
 }
 
 func getWebsocketServices(ing *extensions.Ingress) (wsServices map[string]bool) {
@@ -208,7 +273,8 @@ func getRewrites(ing *extensions.Ingress) (rewrites map[string]string, err error
 		for _, svc := range strings.Split(services, ";") {
 			serviceName, rewrite, err := parseRewrites(svc)
 			if err != nil {
-				return rewrites, err
+				// an invalid entry skips the whole annotation
+				return map[string]string{}, err
 			}
 			rewrites[serviceName] = rewrite
 		}
@@ -233,15 +299,18 @@ func parseRewrites(service string) (serviceName string, rewrite string, err erro
 		return "", "", fmt.Errorf("invalid rewrite format: %s", rwPathParts)
 	}
 
-	return svcNameParts[1], rwPathParts[1], nil
+	rewrite, err = util.ParsePath(rwPathParts[1])
+	if err != nil {
+		return "", "", err
+	}
+	return svcNameParts[1], rewrite, nil
 }
 
-func getSSLServices(ing *extensions.Ingress) (sslServices map[string]bool) {
-	sslServices = make(map[string]bool)
-	if services, exists := ing.Annotations["nginx.org/ssl-services"]; exists {
-		for _, svc := range strings.Split(services, ",") {
-			sslServices[svc] = true
-		}
+// unquote removes the double quotes around the value, for backwards compatibility
+// with annotations that were written to be interpolated verbatim, like "\"Restricted\""
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
 	}
-	return
+	return value
 }
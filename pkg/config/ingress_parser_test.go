@@ -76,7 +76,10 @@ func TestIngParser(t *testing.T) {
 
 		assert.NotNil(ingCfg.WebsocketServices, "WebsocketServices")
 		assert.NotNil(ingCfg.Rewrites, "Rewrites")
-		assert.NotNil(ingCfg.SSLServices, "SSLServices")
+		assert.NotNil(ingCfg.BackendProtocols, "BackendProtocols")
+		assert.Nil(ingCfg.BackendProtocol, "BackendProtocol")
+		assert.Nil(ingCfg.ProxySSLVerify, "ProxySSLVerify")
+		assert.Nil(ingCfg.ProxySSLName, "ProxySSLName")
 	})
 
 	t.Run("invalid nginx.org/location-modifier annotation", func(t *testing.T) {
@@ -119,6 +122,63 @@ func TestIngParser(t *testing.T) {
 			assert.Nil(t, ingCfg.LocationModifier, "LocationModifier")
 		}
 	})
+
+	t.Run("invalid size and time annotations", func(t *testing.T) {
+		ing := &v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ing1",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"nginx.org/proxy-read-timeout":   "10 s",
+					"nginx.org/client-max-body-size": "1t",
+					"nginx.org/proxy-buffers":        "8 4k; return 200",
+					"nginx.org/proxy-buffer-size":    "8k",
+				},
+			},
+		}
+
+		p := NewIngressConfigParser()
+		ingCfg, warning, err := p.Parse(ing)
+		if assert.NoError(t, err) {
+			assert.NotNil(t, warning)
+			assert.Contains(t, warning.Error(), `"nginx.org/proxy-read-timeout"`)
+			assert.Contains(t, warning.Error(), `"nginx.org/client-max-body-size"`)
+			assert.Contains(t, warning.Error(), `"nginx.org/proxy-buffers"`)
+			assert.Nil(t, ingCfg.ProxyReadTimeout, "ProxyReadTimeout")
+			assert.Nil(t, ingCfg.ClientMaxBodySize, "ClientMaxBodySize")
+			assert.Nil(t, ingCfg.ProxyBuffers, "ProxyBuffers")
+			if assert.NotNil(t, ingCfg.ProxyBufferSize) {
+				assert.Equal(t, "8k", *ingCfg.ProxyBufferSize)
+			}
+		}
+	})
+}
+
+func TestIngParserValuesAreValidated(t *testing.T) {
+	ing := &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ing1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"nginx.org/auth-basic":             `"Restricted"`,
+				"nginx.org/auth-basic-user-secret": "users",
+				"nginx.org/proxy-hide-headers":     "X-Powered-By, Server",
+				"nginx.org/proxy-pass-headers":     "X-Foo; return 200",
+				"nginx.org/rewrites":               "serviceName=tea-svc rewrite=/;return 200",
+			},
+		},
+	}
+
+	ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+	if assert.NoError(t, err) {
+		assert.NotNil(t, warning)
+		assert.Contains(t, warning.Error(), `"nginx.org/proxy-pass-headers"`)
+		assert.Contains(t, warning.Error(), `"nginx.org/rewrites"`)
+		assert.Equal(t, "Restricted", ingCfg.BasicAuth)
+		assert.Equal(t, []string{"X-Powered-By", "Server"}, ingCfg.ProxyHideHeaders)
+		assert.Nil(t, ingCfg.ProxyPassHeaders)
+		assert.Empty(t, ingCfg.Rewrites)
+	}
 }
 
 func TestParseRewrites(t *testing.T) {
@@ -142,4 +202,16 @@ func TestParseRewrites(t *testing.T) {
 			t.Errorf("parseRewrites(%s) should return error, got nil", rewriteService)
 		}
 	})
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		for _, rewriteService := range []string{
+			"serviceName=coffee-svc rewrite=beans",
+			"serviceName=coffee-svc rewrite=/{beans}",
+			"serviceName=coffee-svc rewrite=/beans\"",
+		} {
+			if _, _, err := parseRewrites(rewriteService); err == nil {
+				t.Errorf("parseRewrites(%s) should return error, got nil", rewriteService)
+			}
+		}
+	})
 }
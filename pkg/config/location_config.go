@@ -0,0 +1,269 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/thetechnick/nginx-ingress/pkg/util"
+	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// location settings, the annotations use the "nginx.org/" prefix
+const (
+	proxyConnectTimeoutKey  = "proxy-connect-timeout"
+	proxyReadTimeoutKey     = "proxy-read-timeout"
+	clientMaxBodySizeKey    = "client-max-body-size"
+	proxyBufferingKey       = "proxy-buffering"
+	proxyBuffersKey         = "proxy-buffers"
+	proxyBufferSizeKey      = "proxy-buffer-size"
+	proxyMaxTempFileSizeKey = "proxy-max-temp-file-size"
+)
+
+var locationConfigKeys = map[string]bool{
+	proxyConnectTimeoutKey:  true,
+	proxyReadTimeoutKey:     true,
+	clientMaxBodySizeKey:    true,
+	proxyBufferingKey:       true,
+	proxyBuffersKey:         true,
+	proxyBufferSizeKey:      true,
+	proxyMaxTempFileSizeKey: true,
+}
+
+// LocationConfig contains the location settings of an Ingress,
+// nil values are not set and use the value of the next lower level
+type LocationConfig struct {
+	ClientMaxBodySize *string
+	ProxyBuffering    *bool
+	ProxyConnectTimeout,
+	ProxyReadTimeout,
+	ProxyBuffers,
+	ProxyBufferSize,
+	ProxyMaxTempFileSize *string
+}
+
+// merge returns the config with the settings of override applied
+func (c LocationConfig) merge(override *LocationConfig) LocationConfig {
+	if override == nil {
+		return c
+	}
+	if override.ClientMaxBodySize != nil {
+		c.ClientMaxBodySize = override.ClientMaxBodySize
+	}
+	if override.ProxyBuffering != nil {
+		c.ProxyBuffering = override.ProxyBuffering
+	}
+	if override.ProxyConnectTimeout != nil {
+		c.ProxyConnectTimeout = override.ProxyConnectTimeout
+	}
+	if override.ProxyReadTimeout != nil {
+		c.ProxyReadTimeout = override.ProxyReadTimeout
+	}
+	if override.ProxyBuffers != nil {
+		c.ProxyBuffers = override.ProxyBuffers
+	}
+	if override.ProxyBufferSize != nil {
+		c.ProxyBufferSize = override.ProxyBufferSize
+	}
+	if override.ProxyMaxTempFileSize != nil {
+		c.ProxyMaxTempFileSize = override.ProxyMaxTempFileSize
+	}
+	return c
+}
+
+// parseLocationConfig parses the location settings from the map, the keys are prefixed with prefix.
+// Invalid values are skipped and passed to warn.
+func parseLocationConfig(m map[string]string, prefix string, warn func(key string, err error)) LocationConfig {
+	cfg := LocationConfig{}
+	if proxyConnectTimeout, exists, err := util.GetMapKeyAsTime(m, prefix+proxyConnectTimeoutKey); exists {
+		if err != nil {
+			warn(prefix+proxyConnectTimeoutKey, err)
+		} else {
+			cfg.ProxyConnectTimeout = &proxyConnectTimeout
+		}
+	}
+	if proxyReadTimeout, exists, err := util.GetMapKeyAsTime(m, prefix+proxyReadTimeoutKey); exists {
+		if err != nil {
+			warn(prefix+proxyReadTimeoutKey, err)
+		} else {
+			cfg.ProxyReadTimeout = &proxyReadTimeout
+		}
+	}
+	if clientMaxBodySize, exists, err := util.GetMapKeyAsOffset(m, prefix+clientMaxBodySizeKey); exists {
+		if err != nil {
+			warn(prefix+clientMaxBodySizeKey, err)
+		} else {
+			cfg.ClientMaxBodySize = &clientMaxBodySize
+		}
+	}
+	if proxyBuffering, exists, err := util.GetMapKeyAsBool(m, prefix+proxyBufferingKey); exists {
+		if err != nil {
+			warn(prefix+proxyBufferingKey, err)
+		} else {
+			cfg.ProxyBuffering = &proxyBuffering
+		}
+	}
+	if proxyBuffers, exists, err := util.GetMapKeyAsBufferSpec(m, prefix+proxyBuffersKey); exists {
+		if err != nil {
+			warn(prefix+proxyBuffersKey, err)
+		} else {
+			cfg.ProxyBuffers = &proxyBuffers
+		}
+	}
+	if proxyBufferSize, exists, err := util.GetMapKeyAsSize(m, prefix+proxyBufferSizeKey); exists {
+		if err != nil {
+			warn(prefix+proxyBufferSizeKey, err)
+		} else {
+			cfg.ProxyBufferSize = &proxyBufferSize
+		}
+	}
+	if proxyMaxTempFileSize, exists, err := util.GetMapKeyAsSize(m, prefix+proxyMaxTempFileSizeKey); exists {
+		if err != nil {
+			warn(prefix+proxyMaxTempFileSizeKey, err)
+		} else {
+			cfg.ProxyMaxTempFileSize = &proxyMaxTempFileSize
+		}
+	}
+	return cfg
+}
+
+// parseLocationOverrides parses the nginx.org/location-overrides annotation,
+// a JSON object mapping the paths of the Ingress to location settings:
+// {"/upload": {"client-max-body-size": "100m", "proxy-read-timeout": "300s"}}.
+// Invalid paths, unknown keys and invalid values are skipped and passed to warn.
+func parseLocationOverrides(ing *extensions.Ingress, annotation string, warn func(err error)) map[string]*LocationConfig {
+	overrides := map[string]map[string]interface{}{}
+	if err := json.Unmarshal([]byte(annotation), &overrides); err != nil {
+		warn(fmt.Errorf("invalid JSON: %v", err))
+		return nil
+	}
+
+	paths := ingressPaths(ing)
+	sortedPaths := make([]string, 0, len(overrides))
+	for path := range overrides {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
+
+	result := map[string]*LocationConfig{}
+	for _, path := range sortedPaths {
+		if !paths[path] {
+			warn(fmt.Errorf("path %q is not used by the Ingress", path))
+			continue
+		}
+
+		keys := make([]string, 0, len(overrides[path]))
+		for key := range overrides[path] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		settings := map[string]string{}
+		for _, key := range keys {
+			value := overrides[path][key]
+			if !locationConfigKeys[key] {
+				warn(fmt.Errorf("path %q: unknown key %q", path, key))
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				settings[key] = v
+			case bool:
+				settings[key] = strconv.FormatBool(v)
+			case float64:
+				// JSON numbers are decoded as float64, avoid the exponent format of large numbers
+				settings[key] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				warn(fmt.Errorf("path %q: key %q: expected a string, number or bool", path, key))
+			}
+		}
+
+		cfg := parseLocationConfig(settings, "", func(key string, err error) {
+			warn(fmt.Errorf("path %q: key %q: %v", path, key, err))
+		})
+		result[path] = &cfg
+	}
+	return result
+}
+
+// parseServiceOverrides parses the per service variants of the location settings,
+// e.g. nginx.org/proxy-read-timeout-services: "svc-a=300s,svc-b=5s".
+// Unknown services and invalid values are skipped and passed to warn.
+func parseServiceOverrides(ing *extensions.Ingress, warn func(annotation string, err error)) map[string]*LocationConfig {
+	keys := make([]string, 0, len(locationConfigKeys))
+	for key := range locationConfigKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	services := ingressServices(ing)
+	settings := map[string]map[string]string{}
+	for _, key := range keys {
+		annotation := "nginx.org/" + key + "-services"
+		value, exists := ing.Annotations[annotation]
+		if !exists {
+			continue
+		}
+		values, errs := util.ParseServiceMap(value, services)
+		for _, err := range errs {
+			warn(annotation, err)
+		}
+		for service, v := range values {
+			if settings[service] == nil {
+				settings[service] = map[string]string{}
+			}
+			settings[service][key] = v
+		}
+	}
+
+	sortedServices := make([]string, 0, len(settings))
+	for service := range settings {
+		sortedServices = append(sortedServices, service)
+	}
+	sort.Strings(sortedServices)
+
+	result := map[string]*LocationConfig{}
+	for _, service := range sortedServices {
+		cfg := parseLocationConfig(settings[service], "", func(key string, err error) {
+			warn("nginx.org/"+key+"-services", fmt.Errorf("service %q: %v", service, err))
+		})
+		result[service] = &cfg
+	}
+	return result
+}
+
+// ingressServices returns the names of all backend services of the Ingress
+func ingressServices(ing *extensions.Ingress) map[string]bool {
+	services := map[string]bool{}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			services[path.Backend.ServiceName] = true
+		}
+	}
+	if ing.Spec.Backend != nil {
+		services[ing.Spec.Backend.ServiceName] = true
+	}
+	return services
+}
+
+// ingressPaths returns the paths of all rules of the Ingress,
+// the default backend is served at "/"
+func ingressPaths(ing *extensions.Ingress) map[string]bool {
+	paths := map[string]bool{}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			paths[pathOrDefault(path.Path)] = true
+		}
+	}
+	if ing.Spec.Backend != nil {
+		paths["/"] = true
+	}
+	return paths
+}
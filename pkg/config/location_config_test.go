@@ -0,0 +1,146 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func locationOverridesIngress(overrides string) *v1beta1.Ingress {
+	backend := v1beta1.IngressBackend{ServiceName: "svc1", ServicePort: intstr.FromInt(80)}
+	return &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ing1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"nginx.org/client-max-body-size": "10m",
+				"nginx.org/proxy-read-timeout":   "30s",
+				"nginx.org/location-overrides":   overrides,
+			},
+		},
+		Spec: v1beta1.IngressSpec{
+			Rules: []v1beta1.IngressRule{
+				{
+					Host: "one.example.com",
+					IngressRuleValue: v1beta1.IngressRuleValue{
+						HTTP: &v1beta1.HTTPIngressRuleValue{
+							Paths: []v1beta1.HTTPIngressPath{
+								{Path: "/", Backend: backend},
+								{Path: "/upload", Backend: backend},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestLocationOverrides(t *testing.T) {
+	t.Run("are applied after the global config and the ingress annotations", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{"/upload": {"client-max-body-size": "1g", "proxy-buffering": false}}`)
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if !assert.NoError(err) || !assert.NoError(warning) {
+			return
+		}
+		gCfg := NewDefaultConfig()
+		gCfg.ProxyConnectTimeout = "5s"
+		servers, _, err := NewServerConfigParser().Parse(*gCfg, *ingCfg, nil, map[string][]string{})
+		if !assert.NoError(err) || !assert.Len(servers, 1) || !assert.Len(servers[0].Locations, 2) {
+			return
+		}
+
+		upload, root := servers[0].Locations[0], servers[0].Locations[1]
+		assert.Equal("/upload", upload.Path)
+		assert.Equal("1g", upload.ClientMaxBodySize)
+		assert.False(upload.ProxyBuffering)
+		assert.Equal("30s", upload.ProxyReadTimeout)
+		assert.Equal("5s", upload.ProxyConnectTimeout)
+
+		assert.Equal("/", root.Path)
+		assert.Equal("10m", root.ClientMaxBodySize)
+		assert.True(root.ProxyBuffering)
+		assert.Equal("30s", root.ProxyReadTimeout)
+	})
+
+	t.Run("skip unknown paths, unknown keys and invalid values", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{
+			"/upload": {"client-max-body-size": "1t", "proxy-read-timeout": 300, "server-tokens": "off"},
+			"/missing": {"client-max-body-size": "1g"}
+		}`)
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if assert.NoError(err) && assert.Error(warning) {
+			assert.Contains(warning.Error(), `path "/missing" is not used by the Ingress`)
+			assert.Contains(warning.Error(), `unknown key "server-tokens"`)
+			assert.Contains(warning.Error(), `key "client-max-body-size": invalid offset`)
+
+			if assert.Contains(ingCfg.LocationOverrides, "/upload") {
+				upload := ingCfg.LocationOverrides["/upload"]
+				assert.Nil(upload.ClientMaxBodySize)
+				if assert.NotNil(upload.ProxyReadTimeout) {
+					assert.Equal("300", *upload.ProxyReadTimeout)
+				}
+			}
+			assert.NotContains(ingCfg.LocationOverrides, "/missing")
+		}
+	})
+
+	t.Run("large numbers are not formatted with an exponent", func(t *testing.T) {
+		assert := assert.New(t)
+		ing := locationOverridesIngress(`{"/upload": {"client-max-body-size": 100000000, "proxy-read-timeout": 1.5}}`)
+
+		ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+		if assert.NoError(err) && assert.Error(warning) {
+			assert.Contains(warning.Error(), `key "proxy-read-timeout": invalid time "1.5"`)
+			if assert.Contains(ingCfg.LocationOverrides, "/upload") {
+				upload := ingCfg.LocationOverrides["/upload"]
+				if assert.NotNil(upload.ClientMaxBodySize) {
+					assert.Equal("100000000", *upload.ClientMaxBodySize)
+				}
+			}
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		assert := assert.New(t)
+		ingCfg, warning, err := NewIngressConfigParser().Parse(locationOverridesIngress(`{"/upload": "1g"}`))
+		if assert.NoError(err) && assert.Error(warning) {
+			assert.Contains(warning.Error(), "invalid JSON")
+			assert.Nil(ingCfg.LocationOverrides)
+		}
+	})
+}
+
+func TestServiceOverrides(t *testing.T) {
+	assert := assert.New(t)
+	ing := locationOverridesIngress(`{"/upload": {"proxy-read-timeout": "600s"}}`)
+	ing.Spec.Rules[0].HTTP.Paths[1].Backend.ServiceName = "upload-svc"
+	ing.Annotations["nginx.org/proxy-read-timeout-services"] = "upload-svc=300s,svc1=60s"
+	ing.Annotations["nginx.org/client-max-body-size-services"] = "upload-svc=1g,missing-svc=1m"
+	ing.Annotations["nginx.org/proxy-buffers-services"] = "svc1=8"
+
+	ingCfg, warning, err := NewIngressConfigParser().Parse(ing)
+	if !assert.NoError(err) || !assert.Error(warning) {
+		return
+	}
+	assert.Contains(warning.Error(), `Skipping annotation "nginx.org/client-max-body-size-services": unknown service "missing-svc"`)
+	assert.Contains(warning.Error(), `Skipping annotation "nginx.org/proxy-buffers-services": service "svc1": invalid buffer spec`)
+
+	servers, _, err := NewServerConfigParser().Parse(*NewDefaultConfig(), *ingCfg, nil, map[string][]string{})
+	if !assert.NoError(err) || !assert.Len(servers, 1) || !assert.Len(servers[0].Locations, 2) {
+		return
+	}
+	upload, root := servers[0].Locations[0], servers[0].Locations[1]
+	assert.Equal("600s", upload.ProxyReadTimeout, "location overrides take precedence over service overrides")
+	assert.Equal("1g", upload.ClientMaxBodySize)
+	assert.Equal("60s", root.ProxyReadTimeout)
+	assert.Equal("10m", root.ClientMaxBodySize)
+	assert.Equal(NewDefaultConfig().ProxyBuffers, root.ProxyBuffers)
+}
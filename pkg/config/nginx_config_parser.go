@@ -0,0 +1,317 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/thetechnick/nginx-ingress/pkg/apis/nginxingress/v1alpha1"
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
+	"github.com/thetechnick/nginx-ingress/pkg/storage"
+	"github.com/thetechnick/nginx-ingress/pkg/util"
+)
+
+var validSSLProtocols = map[string]bool{
+	"SSLv2":   true,
+	"SSLv3":   true,
+	"TLSv1":   true,
+	"TLSv1.1": true,
+	"TLSv1.2": true,
+	"TLSv1.3": true,
+}
+
+// parseSSLProtocols validates the protocols and joins them for the ssl_protocols directive
+func parseSSLProtocols(protocols []string) (string, error) {
+	invalid := []string{}
+	for _, protocol := range protocols {
+		if !validSSLProtocols[protocol] {
+			invalid = append(invalid, protocol)
+		}
+	}
+	if len(invalid) > 0 {
+		return "", fmt.Errorf("unknown protocols: %s", strings.Join(invalid, ", "))
+	}
+	return strings.Join(protocols, " "), nil
+}
+
+// parseAccessLogPath validates the path of the access log, it must be a file in storage.LogDir,
+// the only log directory the config validator redirects into its sandbox
+func parseAccessLogPath(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, storage.LogDir) {
+		return "", fmt.Errorf("invalid access log path %q, expected a file in %s", s, storage.LogDir)
+	}
+	name := strings.TrimPrefix(s, storage.LogDir)
+	v, err := util.ParseFileName(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid access log path %q: %v", s, err)
+	}
+	if v != name {
+		return "", fmt.Errorf("invalid access log path %q, the file name must not contain whitespace", s)
+	}
+	return s, nil
+}
+
+// NginxIngressConfigParser parses the global config from a NginxIngressConfig
+type NginxIngressConfigParser interface {
+	Parse(cfg *v1alpha1.NginxIngressConfig) (*GlobalConfig, error)
+}
+
+// NewNginxIngressConfigParser returns a new NginxIngressConfigParser
+func NewNginxIngressConfigParser() NginxIngressConfigParser {
+	return &nginxIngressConfigParser{}
+}
+
+type nginxIngressConfigParser struct{}
+
+func (p *nginxIngressConfigParser) Parse(nic *v1alpha1.NginxIngressConfig) (*GlobalConfig, error) {
+	errs := []error{}
+	cfg := NewDefaultConfig()
+	spec := nic.Spec
+
+	if spec.HTTPSnippets != nil {
+		cfg.MainHTTPSnippets = spec.HTTPSnippets
+	}
+	if spec.ServerSnippets != nil {
+		cfg.ServerSnippets = spec.ServerSnippets
+	}
+	if spec.LocationSnippets != nil {
+		cfg.LocationSnippets = spec.LocationSnippets
+	}
+	if spec.SnippetDirectivesAllowlist != nil {
+		cfg.SnippetDirectivesAllowlist = directivePatterns(spec.SnippetDirectivesAllowlist)
+	}
+	if spec.SnippetDirectivesDenylist != nil {
+		cfg.SnippetDirectivesDenylist = directivePatterns(spec.SnippetDirectivesDenylist)
+	}
+	if spec.ServerTokens != nil {
+		cfg.ServerTokens = *spec.ServerTokens
+	}
+	if spec.ServerNamesHashBucketSize != "" {
+		if v, err := util.ParseNumber(spec.ServerNamesHashBucketSize); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.serverNamesHashBucketSize", err})
+		} else {
+			cfg.MainServerNamesHashBucketSize = v
+		}
+	}
+	if spec.ServerNamesHashMaxSize != "" {
+		if v, err := util.ParseNumber(spec.ServerNamesHashMaxSize); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.serverNamesHashMaxSize", err})
+		} else {
+			cfg.MainServerNamesHashMaxSize = v
+		}
+	}
+	if spec.LogFormat != "" {
+		cfg.MainLogFormat = spec.LogFormat
+	}
+	if spec.WorkerShutdownTimeout != "" {
+		if v, err := util.ParseTime(spec.WorkerShutdownTimeout); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.workerShutdownTimeout", err})
+		} else {
+			cfg.MainWorkerShutdownTimeout = v
+		}
+	}
+	if spec.WorkerProcesses != "" {
+		if v, err := util.ParseWorkerProcesses(spec.WorkerProcesses); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.workerProcesses", err})
+		} else {
+			cfg.MainWorkerProcesses = v
+		}
+	}
+	if spec.WorkerConnections != 0 {
+		if v, err := util.ParseNumber(strconv.FormatInt(spec.WorkerConnections, 10)); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.workerConnections", err})
+		} else {
+			cfg.MainWorkerConnections = v
+		}
+	}
+	if spec.WorkerRLimitNofile != 0 {
+		if v, err := util.ParseNumber(strconv.FormatInt(spec.WorkerRLimitNofile, 10)); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.workerRLimitNofile", err})
+		} else {
+			cfg.MainWorkerRLimitNofile = v
+		}
+	}
+	if spec.WorkerCPUAffinity != "" {
+		if v, err := util.ParseCPUAffinity(spec.WorkerCPUAffinity); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.workerCPUAffinity", err})
+		} else {
+			cfg.MainWorkerCPUAffinity = v
+		}
+	}
+	if spec.ErrorLogLevel != "" {
+		if v, err := util.ParseLogLevel(spec.ErrorLogLevel); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.errorLogLevel", err})
+		} else {
+			cfg.MainErrorLogLevel = v
+		}
+	}
+	if spec.KeepaliveTimeout != "" {
+		if v, err := util.ParseTime(spec.KeepaliveTimeout); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.keepaliveTimeout", err})
+		} else {
+			cfg.MainKeepaliveTimeout = v
+		}
+	}
+	if spec.KeepaliveRequests != 0 {
+		if v, err := util.ParseNumber(strconv.FormatInt(spec.KeepaliveRequests, 10)); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.keepaliveRequests", err})
+		} else {
+			cfg.MainKeepaliveRequests = v
+		}
+	}
+	if spec.AccessLogPath != "" {
+		if v, err := parseAccessLogPath(spec.AccessLogPath); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.accessLogPath", err})
+		} else {
+			cfg.MainAccessLogPath = v
+		}
+	}
+	cfg.MainAccessLogOff = spec.AccessLogOff
+	if spec.HTTP2 != nil {
+		cfg.HTTP2 = *spec.HTTP2
+	}
+	if spec.H2C != nil {
+		cfg.H2C = *spec.H2C
+	}
+	if spec.RedirectToHTTPS != nil {
+		cfg.RedirectToHTTPS = *spec.RedirectToHTTPS
+	}
+	if spec.ClientMaxBodySize != "" {
+		if v, err := util.ParseOffset(spec.ClientMaxBodySize); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.clientMaxBodySize", err})
+		} else {
+			cfg.ClientMaxBodySize = v
+		}
+	}
+
+	if spec.ProxyConnectTimeout != "" {
+		if v, err := util.ParseTime(spec.ProxyConnectTimeout); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.proxyConnectTimeout", err})
+		} else {
+			cfg.ProxyConnectTimeout = v
+		}
+	}
+	if spec.ProxyReadTimeout != "" {
+		if v, err := util.ParseTime(spec.ProxyReadTimeout); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.proxyReadTimeout", err})
+		} else {
+			cfg.ProxyReadTimeout = v
+		}
+	}
+	if spec.ProxyBuffering != nil {
+		cfg.ProxyBuffering = *spec.ProxyBuffering
+	}
+	if spec.ProxyBuffers != "" {
+		if v, err := util.ParseBufferSpec(spec.ProxyBuffers); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.proxyBuffers", err})
+		} else {
+			cfg.ProxyBuffers = v
+		}
+	}
+	if spec.ProxyBufferSize != "" {
+		if v, err := util.ParseSize(spec.ProxyBufferSize); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.proxyBufferSize", err})
+		} else {
+			cfg.ProxyBufferSize = v
+		}
+	}
+	if spec.ProxyMaxTempFileSize != "" {
+		if v, err := util.ParseSize(spec.ProxyMaxTempFileSize); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.proxyMaxTempFileSize", err})
+		} else {
+			cfg.ProxyMaxTempFileSize = v
+		}
+	}
+	if spec.ProxyProtocol != nil {
+		cfg.ProxyProtocol = *spec.ProxyProtocol
+	}
+	if spec.ProxyHideHeaders != nil {
+		if v, err := util.ParseList(spec.ProxyHideHeaders, util.ParseHeaderName); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.proxyHideHeaders", err})
+		} else {
+			cfg.ProxyHideHeaders = v
+		}
+	}
+	if spec.ProxyPassHeaders != nil {
+		if v, err := util.ParseList(spec.ProxyPassHeaders, util.ParseHeaderName); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.proxyPassHeaders", err})
+		} else {
+			cfg.ProxyPassHeaders = v
+		}
+	}
+
+	if hsts := spec.HSTS; hsts != nil {
+		if hsts.MaxAge != nil && *hsts.MaxAge < 0 {
+			errs = append(errs, &ConfigFieldError{"spec.hsts.maxAge", fmt.Errorf("must not be negative")})
+			errs = append(errs, fmt.Errorf("Error validating HSTS settings in NginxIngressConfig, skipping all hsts settings"))
+		} else {
+			cfg.HSTS = hsts.Enabled
+			if hsts.MaxAge != nil {
+				cfg.HSTSMaxAge = *hsts.MaxAge
+			}
+			cfg.HSTSIncludeSubdomains = hsts.IncludeSubdomains
+		}
+	}
+
+	if realIP := spec.RealIP; realIP != nil {
+		if realIP.Header != "" {
+			if v, err := util.ParseHeaderName(realIP.Header); err != nil {
+				errs = append(errs, &ConfigFieldError{"spec.realIP.header", err})
+			} else {
+				cfg.RealIPHeader = v
+			}
+		}
+		if v, err := util.ParseList(realIP.SetFrom, util.ParseAddress); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.realIP.setFrom", err})
+		} else if len(v) > 0 {
+			cfg.SetRealIPFrom = v
+		}
+		cfg.RealIPRecursive = realIP.Recursive
+	}
+
+	if ssl := spec.SSL; ssl != nil {
+		if len(ssl.Protocols) > 0 {
+			if v, err := parseSSLProtocols(ssl.Protocols); err != nil {
+				errs = append(errs, &ConfigFieldError{"spec.ssl.protocols", err})
+			} else {
+				cfg.MainServerSSLProtocols = v
+			}
+		}
+		cfg.MainServerSSLPreferServerCiphers = ssl.PreferServerCiphers
+		cfg.MainServerSSLCiphers = strings.Trim(ssl.Ciphers, "\n")
+		cfg.MainServerSSLDHParamFile = strings.Trim(ssl.DHParam, "\n")
+	}
+
+	if compression := spec.Compression; compression != nil {
+		if compression.Gzip != nil {
+			cfg.UseGzip = *compression.Gzip
+		}
+		if compression.GzipLevel != nil {
+			if err := validateGzipLevel(*compression.GzipLevel); err != nil {
+				errs = append(errs, &ConfigFieldError{"spec.compression.gzipLevel", err})
+			} else {
+				cfg.GzipLevel = *compression.GzipLevel
+			}
+		}
+		if compression.GzipMinLength != "" {
+			if v, err := util.ParseSize(compression.GzipMinLength); err != nil {
+				errs = append(errs, &ConfigFieldError{"spec.compression.gzipMinLength", err})
+			} else {
+				cfg.GzipMinLength = v
+			}
+		}
+		if v, err := util.ParseList(compression.GzipTypes, util.ParseMIMEType); err != nil {
+			errs = append(errs, &ConfigFieldError{"spec.compression.gzipTypes", err})
+		} else if len(v) > 0 {
+			cfg.GzipTypes = v
+		}
+		cfg.UseBrotli = compression.Brotli
+	}
+
+	if len(errs) > 0 {
+		return cfg, errors.WrapInObjectContext(ValidationError(errs), nic)
+	}
+
+	return cfg, nil
+}
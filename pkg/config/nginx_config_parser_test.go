@@ -0,0 +1,118 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetechnick/nginx-ingress/pkg/apis/nginxingress/v1alpha1"
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+)
+
+func TestNginxIngressConfigParser(t *testing.T) {
+	p := NewNginxIngressConfigParser()
+
+	t.Run("should emit no errors on empty spec", func(t *testing.T) {
+		assert := assert.New(t)
+		c, err := p.Parse(&v1alpha1.NginxIngressConfig{})
+
+		assert.Nil(err, "A empty spec should not produce errors")
+		if assert.NotNil(c) {
+			assert.Equal(NewDefaultConfig(), c, "Config should be equal to default config")
+		}
+	})
+
+	t.Run("should convert the spec into the same config as the ConfigMap", func(t *testing.T) {
+		assert := assert.New(t)
+		http2 := true
+		maxAge := int64(123)
+		gzipLevel := int64(5)
+
+		c, err := p.Parse(&v1alpha1.NginxIngressConfig{
+			Spec: v1alpha1.NginxIngressConfigSpec{
+				HTTP2:             &http2,
+				H2C:               &http2,
+				ProxyReadTimeout:  "10s",
+				WorkerProcesses:   "8",
+				WorkerConnections: 16384,
+				ErrorLogLevel:     "error",
+				AccessLogOff:      true,
+				ProxyHideHeaders:  []string{"X-Powered-By"},
+				HSTS: &v1alpha1.HSTSSpec{
+					Enabled: true,
+					MaxAge:  &maxAge,
+				},
+				SSL: &v1alpha1.SSLSpec{
+					Protocols: []string{"TLSv1.1", "TLSv1.2"},
+				},
+				Compression: &v1alpha1.CompressionSpec{
+					GzipLevel: &gzipLevel,
+					GzipTypes: []string{"text/css", "application/json"},
+					Brotli:    true,
+				},
+			},
+		})
+		assert.Nil(err)
+
+		cfgmConfig, err := NewConfigMapParser().Parse(&api_v1.ConfigMap{
+			Data: map[string]string{
+				"http2":              "True",
+				"h2c":                "True",
+				"proxy-read-timeout": "10s",
+				"proxy-hide-headers": "X-Powered-By",
+				"hsts":               "True",
+				"hsts-max-age":       "123",
+				"ssl-protocols":      "TLSv1.1 TLSv1.2",
+				"worker-processes":   "8",
+				"worker-connections": "16384",
+				"error-log-level":    "error",
+				"access-log-off":     "true",
+				"gzip-level":         "5",
+				"gzip-types":         "text/css, application/json",
+				"use-brotli":         "true",
+			},
+		})
+		assert.Nil(err)
+		assert.Equal(cfgmConfig, c)
+	})
+
+	t.Run("should skip invalid fields", func(t *testing.T) {
+		assert := assert.New(t)
+		maxAge := int64(-1)
+
+		c, err := p.Parse(&v1alpha1.NginxIngressConfig{
+			Spec: v1alpha1.NginxIngressConfigSpec{
+				HSTS: &v1alpha1.HSTSSpec{
+					Enabled: true,
+					MaxAge:  &maxAge,
+				},
+				SSL: &v1alpha1.SSLSpec{
+					Protocols: []string{"TLSv1.2", "TLSv9"},
+				},
+				Compression: &v1alpha1.CompressionSpec{
+					GzipTypes: []string{"text/css;"},
+				},
+				WorkerConnections:         -1,
+				ServerNamesHashBucketSize: "64k",
+				ServerNamesHashMaxSize:    "512k",
+			},
+		})
+
+		if assert.NotNil(err) && assert.Implements((*errors.ErrObjectContext)(nil), err) {
+			cerr := err.(errors.ErrObjectContext)
+			if assert.IsType(ValidationError{}, cerr.WrappedError()) {
+				verr := cerr.WrappedError().(ValidationError)
+				assert.Len(verr, 7)
+			}
+		}
+
+		if assert.NotNil(c) {
+			assert.False(c.HSTS)
+			assert.Equal("", c.MainServerSSLProtocols)
+			assert.Nil(c.GzipTypes)
+			assert.Equal("1024", c.MainWorkerConnections)
+			assert.Equal("", c.MainServerNamesHashBucketSize)
+			assert.Equal("512", c.MainServerNamesHashMaxSize)
+		}
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/thetechnick/nginx-ingress/pkg/errors"
 	"github.com/thetechnick/nginx-ingress/pkg/storage"
 	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+	"github.com/thetechnick/nginx-ingress/pkg/util"
 	api_v1 "k8s.io/client-go/pkg/api/v1"
 	"k8s.io/client-go/tools/record"
 )
@@ -69,3 +71,95 @@ func (p *basicAuthUserSecretParser) Parse(secret *api_v1.Secret) (*pb.File, erro
 		Content: users,
 	}, nil
 }
+
+// CACertKey is the key of the CA certificate in secrets
+const CACertKey = "ca.crt"
+
+// ProxySSLSecretParser parses secrets that contain the CA certificate to verify the backends
+// and optionally a client certificate to authenticate against the backends
+type ProxySSLSecretParser interface {
+	Parse(secret *api_v1.Secret) (trustedCertificate *pb.File, certificate *pb.File, err error)
+}
+
+// NewProxySSLSecretParser returns a new ProxySSLSecretParser
+func NewProxySSLSecretParser() ProxySSLSecretParser {
+	return &proxySSLSecretParser{}
+}
+
+type proxySSLSecretParser struct{}
+
+func (p *proxySSLSecretParser) Parse(secret *api_v1.Secret) (*pb.File, *pb.File, error) {
+	errs := []error{}
+	ca, ok := secret.Data[CACertKey]
+	if !ok {
+		errs = append(errs, fmt.Errorf("missing CA certificate %q", CACertKey))
+	}
+	cert, hasCert := secret.Data[api_v1.TLSCertKey]
+	key, hasKey := secret.Data[api_v1.TLSPrivateKeyKey]
+	if hasCert != hasKey {
+		errs = append(errs, fmt.Errorf("the client certificate needs both %q and %q", api_v1.TLSCertKey, api_v1.TLSPrivateKeyKey))
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errors.WrapInObjectContext(ValidationError(errs), secret)
+	}
+	trustedCertificate := &pb.File{
+		Name:    path.Join(storage.CertificatesDir, fmt.Sprintf("%s-%s-ca.pem", secret.Namespace, secret.Name)),
+		Content: ca,
+	}
+	var certificate *pb.File
+	if hasCert {
+		certificate = &pb.File{
+			Name:    path.Join(storage.CertificatesDir, fmt.Sprintf("%s-%s-client.pem", secret.Namespace, secret.Name)),
+			Content: bytes.Join([][]byte{cert, key}, []byte("\n")),
+		}
+	}
+	return trustedCertificate, certificate, nil
+}
+
+// AuthSecretLabel marks secrets that contain basic auth users,
+// only secrets with the label set to "true" are watched by the controller
+const AuthSecretLabel = "nginx.org/auth-secret"
+
+// CASecretLabel marks secrets that contain CA certificates to verify the backends,
+// only secrets with the label set to "true" or of the TLS type are watched by the controller
+const CASecretLabel = "nginx.org/ca-secret"
+
+// AllowedNamespacesAnnotation lists the namespaces that are allowed to reference a secret,
+// "*" allows all namespaces
+const AllowedNamespacesAnnotation = "nginx.org/allowed-namespaces"
+
+// SecretAccessDeniedError is returned when a secret is referenced
+// from a namespace that is not granted access to it
+type SecretAccessDeniedError struct {
+	Secret    string
+	Namespace string
+}
+
+func (e *SecretAccessDeniedError) Error() string {
+	return fmt.Sprintf(
+		"access to secret %q from namespace %q denied, the namespace must be listed in the %q annotation of the secret",
+		e.Secret, e.Namespace, AllowedNamespacesAnnotation,
+	)
+}
+
+// CheckSecretAccess checks if the secret may be referenced from the given namespace.
+// Secrets are always accessible from their own namespace,
+// other namespaces must be granted access by the AllowedNamespacesAnnotation.
+func CheckSecretAccess(secret *api_v1.Secret, namespace string) error {
+	if secret.Namespace == namespace {
+		return nil
+	}
+	if allowed, exists := util.GetMapKeyAsStringSlice(secret.Annotations, AllowedNamespacesAnnotation, secret, ","); exists {
+		for _, ns := range allowed {
+			ns = strings.TrimSpace(ns)
+			if ns == "*" || ns == namespace {
+				return nil
+			}
+		}
+	}
+	return &SecretAccessDeniedError{
+		Secret:    secret.Namespace + "/" + secret.Name,
+		Namespace: namespace,
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/thetechnick/nginx-ingress/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	api_v1 "k8s.io/client-go/pkg/api/v1"
 )
 
@@ -40,3 +41,83 @@ func TestTLSSecretParser(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckSecretAccess(t *testing.T) {
+	secret := func(annotations map[string]string) *api_v1.Secret {
+		return &api_v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "users",
+				Namespace:   "auth",
+				Annotations: annotations,
+			},
+		}
+	}
+
+	t.Run("allows access from the same namespace", func(t *testing.T) {
+		assert.NoError(t, CheckSecretAccess(secret(nil), "auth"))
+	})
+
+	t.Run("denies access from other namespaces by default", func(t *testing.T) {
+		err := CheckSecretAccess(secret(nil), "tenant")
+		if assert.IsType(t, &SecretAccessDeniedError{}, err) {
+			assert.Equal(t, "auth/users", err.(*SecretAccessDeniedError).Secret)
+			assert.Equal(t, "tenant", err.(*SecretAccessDeniedError).Namespace)
+		}
+	})
+
+	t.Run("allows access from listed namespaces", func(t *testing.T) {
+		s := secret(map[string]string{AllowedNamespacesAnnotation: "team-a, tenant"})
+		assert.NoError(t, CheckSecretAccess(s, "tenant"))
+		assert.Error(t, CheckSecretAccess(s, "team-b"))
+	})
+
+	t.Run("allows access from all namespaces with a wildcard", func(t *testing.T) {
+		s := secret(map[string]string{AllowedNamespacesAnnotation: "*"})
+		assert.NoError(t, CheckSecretAccess(s, "tenant"))
+	})
+}
+
+func TestProxySSLSecretParser(t *testing.T) {
+	p := NewProxySSLSecretParser()
+	secret := func(data map[string][]byte) *api_v1.Secret {
+		return &api_v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+			Data:       data,
+		}
+	}
+
+	t.Run("should return all errors", func(t *testing.T) {
+		assert := assert.New(t)
+		trusted, cert, err := p.Parse(secret(map[string][]byte{api_v1.TLSCertKey: []byte("cert")}))
+		if assert.Implements((*errors.ErrObjectContext)(nil), err) {
+			assert.Len(err.(errors.ErrObjectContext).WrappedError().(ValidationError), 2)
+		}
+		assert.Nil(trusted)
+		assert.Nil(cert)
+	})
+
+	t.Run("should return the CA certificate", func(t *testing.T) {
+		assert := assert.New(t)
+		trusted, cert, err := p.Parse(secret(map[string][]byte{CACertKey: []byte("ca")}))
+		assert.NoError(err)
+		if assert.NotNil(trusted) {
+			assert.Equal("/etc/nginx/ssl/default-backend-ca.pem", trusted.Name)
+			assert.Equal([]byte("ca"), trusted.Content)
+		}
+		assert.Nil(cert)
+	})
+
+	t.Run("should return the client certificate", func(t *testing.T) {
+		assert := assert.New(t)
+		_, cert, err := p.Parse(secret(map[string][]byte{
+			CACertKey:               []byte("ca"),
+			api_v1.TLSCertKey:       []byte("cert"),
+			api_v1.TLSPrivateKeyKey: []byte("key"),
+		}))
+		assert.NoError(err)
+		if assert.NotNil(cert) {
+			assert.Equal("/etc/nginx/ssl/default-backend-client.pem", cert.Name)
+			assert.Equal([]byte("cert\nkey"), cert.Content)
+		}
+	})
+}
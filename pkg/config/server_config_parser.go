@@ -6,6 +6,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+	"github.com/thetechnick/nginx-ingress/pkg/util"
 	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
 )
 
@@ -57,6 +58,11 @@ func (p *serverConfigParser) Parse(
 		rootLocation := false
 
 		for _, path := range rule.HTTP.Paths {
+			if _, err := util.ParseLocationPath(pathOrDefault(path.Path)); err != nil {
+				warnings = append(warnings, fmt.Errorf("Skipping path of host %q: %v", rule.Host, err))
+				continue
+			}
+
 			upsName := getNameForUpstream(ing, rule.Host, path.Backend.ServiceName)
 
 			if _, exists := upstreams[upsName]; !exists {
@@ -76,9 +82,11 @@ func (p *serverConfigParser) Parse(
 				upstreams[upsName],
 				&gCfg,
 				&ingCfg,
+				ingCfg.ServiceOverrides[path.Backend.ServiceName],
+				ingCfg.LocationOverrides[pathOrDefault(path.Path)],
 				ingCfg.WebsocketServices[path.Backend.ServiceName],
 				ingCfg.Rewrites[path.Backend.ServiceName],
-				ingCfg.SSLServices[path.Backend.ServiceName],
+				ingCfg.backendProtocol(path.Backend.ServiceName),
 			)
 			locations = append(locations, loc)
 
@@ -94,13 +102,16 @@ func (p *serverConfigParser) Parse(
 				upstreams[upsName],
 				&gCfg,
 				&ingCfg,
+				ingCfg.ServiceOverrides[ing.Spec.Backend.ServiceName],
+				ingCfg.LocationOverrides["/"],
 				ingCfg.WebsocketServices[ing.Spec.Backend.ServiceName],
 				ingCfg.Rewrites[ing.Spec.Backend.ServiceName],
-				ingCfg.SSLServices[ing.Spec.Backend.ServiceName],
+				ingCfg.backendProtocol(ing.Spec.Backend.ServiceName),
 			)
 			locations = append(locations, loc)
 		}
 
+		SortLocations(locations)
 		server := CreateServerConfig(&gCfg, &ingCfg)
 		server.Name = serverName
 		server.Locations = locations
@@ -111,6 +122,16 @@ func (p *serverConfigParser) Parse(
 			server.SSLCertificateKey = pemFile.Name
 			server.Files = append(server.Files, pemFile)
 		}
+		if HasGRPCLocation(server.Locations) {
+			// gRPC requires HTTP/2
+			server.HTTP2 = true
+			if !server.SSL && !server.H2C {
+				warnings = append(warnings, fmt.Errorf(
+					"gRPC services of host %q need HTTP/2, which is only enabled for TLS or with the h2c setting of the global config",
+					serverName,
+				))
+			}
+		}
 		servers = append(servers, server)
 	}
 
@@ -125,9 +146,11 @@ func (p *serverConfigParser) Parse(
 			upstream,
 			&gCfg,
 			&ingCfg,
+			ingCfg.ServiceOverrides[ing.Spec.Backend.ServiceName],
+			ingCfg.LocationOverrides["/"],
 			ingCfg.WebsocketServices[ing.Spec.Backend.ServiceName],
 			ingCfg.Rewrites[ing.Spec.Backend.ServiceName],
-			ingCfg.SSLServices[ing.Spec.Backend.ServiceName],
+			ingCfg.backendProtocol(ing.Spec.Backend.ServiceName),
 		)
 
 		server := CreateServerConfig(&gCfg, &ingCfg)
@@ -142,6 +165,13 @@ func (p *serverConfigParser) Parse(
 		}
 	}
 
+	if ingCfg.Compression.UseBrotli != nil && *ingCfg.Compression.UseBrotli && !gCfg.UseBrotli {
+		warnings = append(warnings, &IngressAnnotationError{
+			"nginx.org/use-brotli",
+			fmt.Errorf("brotli is not enabled by the use-brotli setting of the global config"),
+		})
+	}
+
 	if len(warnings) > 0 {
 		warning = ValidationError(warnings)
 	}
@@ -153,6 +183,7 @@ func upstreamMapToList(upstreams map[string]Upstream) (result []Upstream) {
 	for _, up := range upstreams {
 		result = append(result, up)
 	}
+	SortUpstreams(result)
 	return
 }
 
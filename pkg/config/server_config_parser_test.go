@@ -165,4 +165,117 @@ func TestServerConfigParser(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Invalid path", func(t *testing.T) {
+		assert := assert.New(t)
+		p := NewServerConfigParser()
+		backend := v1beta1.IngressBackend{
+			ServiceName: "svc1",
+			ServicePort: intstr.FromInt(9000),
+		}
+		ing := &v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ing1",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					v1beta1.IngressRule{
+						Host: "one.example.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									v1beta1.HTTPIngressPath{Path: "/", Backend: backend},
+									v1beta1.HTTPIngressPath{Path: "/a { return 200; }", Backend: backend},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		servers, warning, err := p.Parse(
+			*NewDefaultConfig(),
+			IngressConfig{Ingress: ing},
+			nil,
+			map[string][]string{
+				"svc19000": []string{"8.8.8.8:9000"},
+			},
+		)
+
+		if assert.NoError(err) && assert.Error(warning) {
+			assert.Contains(warning.Error(), "invalid path")
+			if assert.Len(servers, 1) && assert.Len(servers[0].Locations, 1) {
+				assert.Equal("/", servers[0].Locations[0].Path)
+			}
+		}
+	})
+
+	t.Run("gRPC services enable HTTP2", func(t *testing.T) {
+		ing := &v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ing1",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					v1beta1.IngressRule{
+						Host: "one.example.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									v1beta1.HTTPIngressPath{
+										Path: "/helloworld.Greeter",
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "grpc-svc",
+											ServicePort: intstr.FromInt(50051),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		endpoints := map[string][]string{
+			"grpc-svc50051": []string{"8.8.8.8:50051"},
+		}
+		for _, c := range []struct {
+			name     string
+			tlsCerts map[string]*pb.File
+			h2c      bool
+			warning  bool
+		}{
+			{name: "TLS", tlsCerts: map[string]*pb.File{"one.example.com": &pb.File{Name: "ssl/one.example.com"}}},
+			{name: "h2c", h2c: true},
+			{name: "without TLS or h2c", warning: true},
+		} {
+			t.Run(c.name, func(t *testing.T) {
+				assert := assert.New(t)
+				ingCfg := IngressConfig{
+					Ingress:          ing,
+					BackendProtocols: map[string]BackendProtocol{"grpc-svc": BackendProtocolGRPC},
+				}
+				gCfg := NewDefaultConfig()
+				gCfg.H2C = c.h2c
+
+				servers, warning, err := NewServerConfigParser().Parse(*gCfg, ingCfg, c.tlsCerts, endpoints)
+				if assert.NoError(err) && assert.Len(servers, 1) {
+					assert.True(servers[0].HTTP2, "HTTP2 should be active")
+					if assert.Len(servers[0].Locations, 1) {
+						assert.True(servers[0].Locations[0].GRPC(), "location should be gRPC")
+					}
+				}
+				if c.warning {
+					if assert.Error(warning) {
+						assert.Contains(warning.Error(), "need HTTP/2")
+					}
+				} else {
+					assert.NoError(warning)
+				}
+			})
+		}
+	})
 }
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
+)
+
+// snippet annotations checked by ValidateSnippetAnnotations
+const (
+	serverSnippetsAnnotation   = "nginx.org/server-snippets"
+	locationSnippetsAnnotation = "nginx.org/location-snippets"
+)
+
+// SnippetDirectives returns the names of all directives used in the snippet,
+// including the directives nested in blocks.
+// An error is returned if the snippet is not well formed,
+// e.g. has unbalanced braces or an unterminated directive or string.
+func SnippetDirectives(snippet string) ([]string, error) {
+	tokens, err := tokenize(snippet)
+	if err != nil {
+		return nil, err
+	}
+
+	directives := []string{}
+	depth := 0
+	statement := false
+	for _, t := range tokens {
+		if t.quoted {
+			if !statement {
+				return nil, fmt.Errorf("line %d: directive name must not be quoted", t.line)
+			}
+			continue
+		}
+
+		switch t.value {
+		case ";":
+			if !statement {
+				return nil, fmt.Errorf("line %d: unexpected \";\"", t.line)
+			}
+			statement = false
+		case "{":
+			if !statement {
+				return nil, fmt.Errorf("line %d: unexpected \"{\"", t.line)
+			}
+			statement = false
+			depth++
+		case "}":
+			if statement {
+				return nil, fmt.Errorf("line %d: directive is not terminated by \";\"", t.line)
+			}
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unexpected \"}\"", t.line)
+			}
+			depth--
+		default:
+			if !statement {
+				directives = append(directives, t.value)
+				statement = true
+			}
+		}
+	}
+
+	if statement {
+		return nil, fmt.Errorf("directive is not terminated by \";\"")
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("unexpected end of snippet, expecting \"}\"")
+	}
+	return directives, nil
+}
+
+type token struct {
+	value  string
+	quoted bool
+	line   int
+}
+
+// tokenize splits a nginx config snippet into tokens,
+// comments are dropped, quotes and escapes are resolved.
+func tokenize(s string) ([]token, error) {
+	tokens := []token{}
+	line := 1
+	var current []rune
+	inToken := false
+
+	flush := func() {
+		if inToken {
+			tokens = append(tokens, token{value: string(current), line: line})
+			current = nil
+			inToken = false
+		}
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			flush()
+			line++
+		case r == ' ' || r == '\t' || r == '\r':
+			flush()
+		case r == '#' && !inToken:
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			i--
+		case r == ';' || r == '{' || r == '}':
+			flush()
+			tokens = append(tokens, token{value: string(r), line: line})
+		case (r == '"' || r == '\'') && !inToken:
+			start := line
+			value := []rune{}
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					value = append(value, runes[i])
+					continue
+				}
+				if runes[i] == '\n' {
+					line++
+				}
+				if runes[i] == r {
+					closed = true
+					break
+				}
+				value = append(value, runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated string", start)
+			}
+			tokens = append(tokens, token{value: string(value), quoted: true, line: start})
+		case r == '\\' && i+1 < len(runes):
+			i++
+			current = append(current, runes[i])
+			inToken = true
+		default:
+			current = append(current, r)
+			inToken = true
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+// directivePatterns trims the patterns of a allow- or denylist and drops empty entries
+func directivePatterns(list []string) []string {
+	patterns := []string{}
+	for _, pattern := range list {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// matchDirective checks if the directive name matches one of the patterns,
+// patterns may contain shell style wildcards like "*_by_lua*"
+func matchDirective(patterns []string, directive string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, directive); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSnippet checks all directives of the snippet against the allow- and denylist
+func validateSnippet(snippet string, allowlist, denylist []string) error {
+	directives, err := SnippetDirectives(snippet)
+	if err != nil {
+		return err
+	}
+
+	for _, directive := range directives {
+		if matchDirective(denylist, directive) {
+			return fmt.Errorf("directive %q is not allowed", directive)
+		}
+		if len(allowlist) > 0 && !matchDirective(allowlist, directive) {
+			return fmt.Errorf("directive %q is not allowed", directive)
+		}
+	}
+	return nil
+}
+
+// ValidateSnippetAnnotations removes the snippets of the ingress config
+// that are not allowed by the global config and returns a warning for each rejected annotation.
+// If allowAnnotations is false, all snippet annotations are rejected.
+func ValidateSnippetAnnotations(gCfg *GlobalConfig, ingCfg *IngressConfig, allowAnnotations bool) (warning error) {
+	warnings := []error{}
+
+	check := func(annotation string, snippets []string) bool {
+		if snippets == nil {
+			return true
+		}
+		if !allowAnnotations {
+			warnings = append(warnings, &IngressAnnotationError{annotation, fmt.Errorf("snippet annotations are disabled")})
+			return false
+		}
+		if err := validateSnippet(strings.Join(snippets, "\n"), gCfg.SnippetDirectivesAllowlist, gCfg.SnippetDirectivesDenylist); err != nil {
+			warnings = append(warnings, &IngressAnnotationError{annotation, err})
+			return false
+		}
+		return true
+	}
+
+	if !check(serverSnippetsAnnotation, ingCfg.ServerSnippets) {
+		ingCfg.ServerSnippets = nil
+	}
+	if !check(locationSnippetsAnnotation, ingCfg.LocationSnippets) {
+		ingCfg.LocationSnippets = nil
+	}
+
+	if len(warnings) > 0 {
+		warning = errors.WrapInObjectContext(ValidationError(warnings), ingCfg.Ingress)
+	}
+	return
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func TestSnippetDirectives(t *testing.T) {
+	t.Run("returns all directives", func(t *testing.T) {
+		assert := assert.New(t)
+		directives, err := SnippetDirectives(`
+# comment; with { braces
+add_header X-Frame-Options "SAMEORIGIN; {";
+if ($http_x_test = 'a\'b') {
+	return 301 https://$host$request_uri;
+}
+set $a\;b c;`)
+
+		if assert.NoError(err) {
+			assert.Equal([]string{"add_header", "if", "return", "set"}, directives)
+		}
+	})
+
+	invalid := map[string]string{
+		"unterminated directive": "add_header X-Test test",
+		"unterminated string":    `add_header X-Test "test;`,
+		"unbalanced braces":      "} server { listen 8080;",
+		"unclosed block":         "if ($a) { return 404;",
+		"quoted directive":       `"include" /etc/nginx/ssl/*;`,
+		"empty statement":        "listen 80;;",
+		"block without name":     "{ return 404; }",
+	}
+	for name, snippet := range invalid {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := SnippetDirectives(snippet)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateSnippetAnnotations(t *testing.T) {
+	ing := &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ing1",
+			Namespace: "default",
+		},
+	}
+
+	t.Run("accepts snippets without allow- and denylist", func(t *testing.T) {
+		assert := assert.New(t)
+		ingCfg := &IngressConfig{
+			Ingress:          ing,
+			ServerSnippets:   []string{"if ($a) {", "  return 404;", "}"},
+			LocationSnippets: []string{"add_header X-Test test;"},
+		}
+
+		assert.NoError(ValidateSnippetAnnotations(NewDefaultConfig(), ingCfg, true))
+		assert.Len(ingCfg.ServerSnippets, 3)
+		assert.Len(ingCfg.LocationSnippets, 1)
+	})
+
+	t.Run("rejects all snippet annotations if they are disabled", func(t *testing.T) {
+		assert := assert.New(t)
+		ingCfg := &IngressConfig{
+			Ingress:          ing,
+			ServerSnippets:   []string{"add_header X-Test test;"},
+			LocationSnippets: []string{"add_header X-Test test;"},
+		}
+
+		warning := ValidateSnippetAnnotations(NewDefaultConfig(), ingCfg, false)
+		if assert.Error(warning) {
+			verr := warning.(errors.ErrObjectContext).WrappedError().(ValidationError)
+			assert.Len(verr, 2)
+		}
+		assert.Nil(ingCfg.ServerSnippets)
+		assert.Nil(ingCfg.LocationSnippets)
+	})
+
+	t.Run("applies the allow- and denylist", func(t *testing.T) {
+		assert := assert.New(t)
+		gCfg := NewDefaultConfig()
+		gCfg.SnippetDirectivesAllowlist = []string{"add_header", "return", "*_by_lua*"}
+		gCfg.SnippetDirectivesDenylist = []string{"*lua*"}
+		ingCfg := &IngressConfig{
+			Ingress:          ing,
+			ServerSnippets:   []string{"add_header X-Test test;", "return 404;"},
+			LocationSnippets: []string{"content_by_lua_block {", "}"},
+		}
+
+		warning := ValidateSnippetAnnotations(gCfg, ingCfg, true)
+		if assert.Error(warning) {
+			assert.Contains(warning.Error(), `Skipping annotation "nginx.org/location-snippets": directive "content_by_lua_block" is not allowed`)
+		}
+		assert.Len(ingCfg.ServerSnippets, 2)
+		assert.Nil(ingCfg.LocationSnippets)
+
+		ingCfg.ServerSnippets = []string{"include /etc/nginx/ssl/*;"}
+		warning = ValidateSnippetAnnotations(gCfg, ingCfg, true)
+		if assert.Error(warning) {
+			assert.Contains(warning.Error(), `directive "include" is not allowed`)
+		}
+		assert.Nil(ingCfg.ServerSnippets)
+	})
+}
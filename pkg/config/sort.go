@@ -0,0 +1,60 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+)
+
+// The rendered config must only change if the input changes,
+// otherwise unchanged server configs are published and nginx is reloaded.
+// Servers are therefore rendered in the following order:
+//
+// * exact and prefix locations, longest path first, as nginx uses the longest matching prefix,
+// * regex locations in declaration order, as nginx uses the first matching regex,
+// * upstreams and files by name.
+
+// isRegexLocation checks if the location path uses a regex modifier
+func isRegexLocation(path string) bool {
+	return strings.HasPrefix(path, "~")
+}
+
+// locationPath strips the modifier from the location path
+func locationPath(path string) string {
+	if fields := strings.Fields(path); len(fields) > 1 {
+		return fields[len(fields)-1]
+	}
+	return path
+}
+
+// SortLocations sorts the locations in the documented order,
+// regex locations keep their relative order
+func SortLocations(locations []Location) {
+	sort.SliceStable(locations, func(i, j int) bool {
+		iRegex, jRegex := isRegexLocation(locations[i].Path), isRegexLocation(locations[j].Path)
+		if iRegex || jRegex {
+			return !iRegex && jRegex
+		}
+
+		iPath, jPath := locationPath(locations[i].Path), locationPath(locations[j].Path)
+		if len(iPath) != len(jPath) {
+			return len(iPath) > len(jPath)
+		}
+		return locations[i].Path < locations[j].Path
+	})
+}
+
+// SortUpstreams sorts the upstreams by name
+func SortUpstreams(upstreams []Upstream) {
+	sort.Slice(upstreams, func(i, j int) bool {
+		return upstreams[i].Name < upstreams[j].Name
+	})
+}
+
+// SortFiles sorts the files by name
+func SortFiles(files []*pb.File) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortLocations(t *testing.T) {
+	locations := []Location{
+		{Path: "~ ^/api/v[0-9]+/orders"},
+		{Path: "/"},
+		{Path: "~* \\.(css|js)$"},
+		{Path: "/tea"},
+		{Path: "= /health"},
+		{Path: "~ ^/api"},
+		{Path: "/coffee"},
+		{Path: "/tea/green"},
+	}
+	SortLocations(locations)
+
+	paths := []string{}
+	for _, location := range locations {
+		paths = append(paths, location.Path)
+	}
+	assert.Equal(t, []string{
+		"/tea/green",
+		"/coffee",
+		"= /health",
+		"/tea",
+		"/",
+		"~ ^/api/v[0-9]+/orders",
+		"~* \\.(css|js)$",
+		"~ ^/api",
+	}, paths)
+}
+
+func TestSortUpstreams(t *testing.T) {
+	upstreams := []Upstream{{Name: "b"}, {Name: "c"}, {Name: "a"}}
+	SortUpstreams(upstreams)
+	assert.Equal(t, []Upstream{{Name: "a"}, {Name: "b"}, {Name: "c"}}, upstreams)
+}
@@ -31,6 +31,19 @@ func (a *secretAccessorFuncs) Get(namespace, name string) (*api_v1.Secret, error
 	return a.get(namespace, name)
 }
 
+// ConfigMapAccessor contains methods to access k8s config maps
+type ConfigMapAccessor interface {
+	Get(namespace, name string) (*api_v1.ConfigMap, error)
+}
+
+type configMapAccessorFuncs struct {
+	get func(namespace, name string) (*api_v1.ConfigMap, error)
+}
+
+func (a *configMapAccessorFuncs) Get(namespace, name string) (*api_v1.ConfigMap, error) {
+	return a.get(namespace, name)
+}
+
 // EndpointsAccessor contains methods to access k8s service endpoints
 type EndpointsAccessor interface {
 	GetEndpointsForIngressBackend(backend *extensions.IngressBackend, namespace string) ([]string, error)
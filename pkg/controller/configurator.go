@@ -3,38 +3,63 @@ package controller
 import (
 	"fmt"
 	"path"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/thetechnick/nginx-ingress/pkg/apis/nginxingress/v1alpha1"
 	"github.com/thetechnick/nginx-ingress/pkg/collision"
 	"github.com/thetechnick/nginx-ingress/pkg/config"
 	"github.com/thetechnick/nginx-ingress/pkg/errors"
 	"github.com/thetechnick/nginx-ingress/pkg/renderer"
 	"github.com/thetechnick/nginx-ingress/pkg/storage"
 	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+	"github.com/thetechnick/nginx-ingress/pkg/validation"
 	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	api_v1 "k8s.io/client-go/pkg/api/v1"
 	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 )
 
 // Configurator converts ingress objects into nginx config
 type Configurator interface {
 	ConfigUpdated(cfgm *api_v1.ConfigMap) error
+	NginxConfigUpdated(cfg *v1alpha1.NginxIngressConfig) (warning error, err error)
+	// NginxConfigDeleted falls back to the given ConfigMap, or the default config if it is nil,
+	// after the NginxIngressConfig with the given key was deleted
+	NginxConfigDeleted(nicKey string, cfgm *api_v1.ConfigMap) error
 	IngressDeleted(ingKey string) error
 	IngressUpdated(ingKey string) error
+	// TemplatesUpdated replaces the templates loaded from files, templates of the main config take
+	// precedence. A *renderer.TemplateError is returned if they are invalid, the previous templates are kept.
+	TemplatesUpdated(mainTemplate, ingressTemplate string) error
+}
+
+// templates are the custom templates, empty templates are replaced by the embedded defaults
+type templates struct {
+	main    string
+	ingress string
 }
 
 // NewConfigurator creates a new Configurator instance
 func NewConfigurator(
 	ingressAccessor IngressAccessor,
 	secretAccessor SecretAccessor,
+	configMapAccessor ConfigMapAccessor,
 	endpointsAccessor EndpointsAccessor,
 
 	secretWatchlist Watchlist,
+	configMapWatchlist Watchlist,
 
 	recorder record.EventRecorder,
+	validator validation.Validator,
+	allowSnippetAnnotations bool,
+	collisionHandler collision.Handler,
 	mcs storage.MainConfigStorage,
 	scs storage.ServerConfigStorage,
 ) Configurator {
@@ -44,29 +69,47 @@ func NewConfigurator(
 
 		ingressAccessor:   ingressAccessor,
 		secretAccessor:    secretAccessor,
+		configMapAccessor: configMapAccessor,
 		endpointsAccessor: endpointsAccessor,
 
-		secretWatchlist: secretWatchlist,
+		secretWatchlist:    secretWatchlist,
+		configMapWatchlist: configMapWatchlist,
 
 		ingParser:                 config.NewIngressConfigParser(),
 		tlsSecretParser:           config.NewTLSSecretParser(),
 		configMapParser:           config.NewConfigMapParser(),
+		nginxConfigParser:         config.NewNginxIngressConfigParser(),
 		serverConfigParser:        config.NewServerConfigParser(),
 		basicAuthUserSecretParser: config.NewBasicAuthUserSecretParser(),
+		proxySSLSecretParser:      config.NewProxySSLSecretParser(),
 
-		ch:           collision.NewMergingCollisionHandler(),
+		ch:           collisionHandler,
 		configurator: renderer.NewRenderer(),
-		recorder:     recorder,
+		events:       NewEventReporter(recorder),
+		validator:    validator,
 		log:          log.WithField("module", "Configurator"),
+
+		allowSnippetAnnotations: allowSnippetAnnotations,
 	}
 }
 
 type configurator struct {
 	log        *log.Entry
 	mainConfig *config.GlobalConfig
-	mutex      sync.Mutex
-
-	secretWatchlist Watchlist
+	// last rendered main config, used to validate server configs
+	renderedMainConfig *pb.MainConfig
+	// object the main config was loaded from, template errors are reported on it
+	mainConfigObject runtime.Object
+	// templates loaded from files and templates used by the renderer
+	templateFiles    templates
+	appliedTemplates templates
+	// mutex protects the main config, ingress updates share the read lock
+	mutex sync.RWMutex
+	// hostLocks serializes updates of ingresses sharing hosts
+	hostLocks hostLocks
+
+	secretWatchlist    Watchlist
+	configMapWatchlist Watchlist
 
 	mcs storage.MainConfigStorage
 	scs storage.ServerConfigStorage
@@ -74,17 +117,23 @@ type configurator struct {
 	// k8s accessors
 	ingressAccessor   IngressAccessor
 	secretAccessor    SecretAccessor
+	configMapAccessor ConfigMapAccessor
 	endpointsAccessor EndpointsAccessor
 
 	tlsSecretParser           config.TLSSecretParser
 	ingParser                 config.IngressConfigParser
 	configMapParser           config.ConfigMapParser
+	nginxConfigParser         config.NginxIngressConfigParser
 	serverConfigParser        config.ServerConfigParser
 	basicAuthUserSecretParser config.BasicAuthUserSecretParser
+	proxySSLSecretParser      config.ProxySSLSecretParser
 
 	ch           collision.Handler
 	configurator renderer.Renderer
-	recorder     record.EventRecorder
+	events       EventReporter
+	validator    validation.Validator
+
+	allowSnippetAnnotations bool
 }
 
 func (c *configurator) ConfigUpdated(cfgm *api_v1.ConfigMap) error {
@@ -93,12 +142,92 @@ func (c *configurator) ConfigUpdated(cfgm *api_v1.ConfigMap) error {
 
 	nginxConfig, err := c.configMapParser.Parse(cfgm)
 	if err != nil {
-		c.recordError("Config Error", err)
+		c.events.Report(ReasonInvalidConfig, err)
 		if nginxConfig == nil {
 			return err
 		}
+	} else {
+		c.events.Clear(cfgm)
+	}
+	return c.applyGlobalConfig(nginxConfig, cfgm)
+}
+
+func (c *configurator) NginxConfigUpdated(cfg *v1alpha1.NginxIngressConfig) (warning error, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	nginxConfig, warning := c.nginxConfigParser.Parse(cfg)
+	if warning != nil {
+		c.events.Report(ReasonInvalidConfig, warning)
+		if nginxConfig == nil {
+			return warning, warning
+		}
+	} else {
+		c.events.Clear(cfg)
+	}
+	return warning, c.applyGlobalConfig(nginxConfig, cfg)
+}
+
+func (c *configurator) NginxConfigDeleted(nicKey string, cfgm *api_v1.ConfigMap) error {
+	if namespace, name, err := cache.SplitMetaNamespaceKey(nicKey); err == nil {
+		c.events.Forget(&v1alpha1.NginxIngressConfig{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}})
+	}
+	if cfgm != nil {
+		return c.ConfigUpdated(cfgm)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.applyGlobalConfig(config.NewDefaultConfig(), nil)
+}
+
+func (c *configurator) TemplatesUpdated(mainTemplate, ingressTemplate string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.templateFiles = templates{main: mainTemplate, ingress: ingressTemplate}
+	if err := c.applyTemplates(); err != nil {
+		return err
+	}
+	if c.mainConfig == nil {
+		return nil
+	}
+	return c.applyGlobalConfig(c.mainConfig, c.mainConfigObject)
+}
+
+// applyTemplates updates the templates of the renderer if they changed,
+// invalid templates are reported and the previous templates are kept.
+// The caller must hold the mutex.
+func (c *configurator) applyTemplates() error {
+	t := c.templateFiles
+	if c.mainConfig != nil && c.mainConfig.MainTemplate != "" {
+		t.main = c.mainConfig.MainTemplate
+	}
+	if c.mainConfig != nil && c.mainConfig.IngressTemplate != "" {
+		t.ingress = c.mainConfig.IngressTemplate
+	}
+	if t == c.appliedTemplates {
+		return nil
+	}
+
+	if err := c.configurator.UpdateTemplates(t.main, t.ingress); err != nil {
+		c.log.WithError(err).Error("Invalid templates, keeping the previous templates")
+		if c.mainConfigObject != nil {
+			c.events.Report(ReasonInvalidTemplate, errors.WrapInObjectContext(err, c.mainConfigObject))
+		}
+		return err
 	}
+	c.appliedTemplates = t
+	return nil
+}
+
+// applyGlobalConfig renders and stores the main config loaded from the given object,
+// the caller must hold the mutex
+func (c *configurator) applyGlobalConfig(nginxConfig *config.GlobalConfig, obj runtime.Object) error {
 	c.mainConfig = nginxConfig
+	c.mainConfigObject = obj
+	// invalid templates are reported, rendering continues with the previous templates
+	c.applyTemplates()
 
 	configUpdate, err := c.configurator.RenderMainConfig(
 		renderer.MainConfigTemplateDataFromIngressConfig(nginxConfig),
@@ -106,10 +235,23 @@ func (c *configurator) ConfigUpdated(cfgm *api_v1.ConfigMap) error {
 	if err != nil {
 		return err
 	}
+	c.renderedMainConfig = configUpdate
 	return c.mcs.Put(configUpdate)
 }
 
+// validateServerConfig tests the server config together with the current main config,
+// returns a *validation.ConfigError if nginx rejects the config
+func (c *configurator) validateServerConfig(serverConfig *pb.ServerConfig) error {
+	if c.validator == nil || c.renderedMainConfig == nil {
+		return nil
+	}
+	return c.validator.ValidateServerConfig(c.renderedMainConfig, serverConfig)
+}
+
 func (c *configurator) IngressDeleted(deletedIngressKey string) error {
+	if namespace, name, err := cache.SplitMetaNamespaceKey(deletedIngressKey); err == nil {
+		c.events.Forget(&v1beta1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}})
+	}
 	return c.IngressUpdated(deletedIngressKey)
 }
 
@@ -186,43 +328,34 @@ func dependencyMapLogEntry(dependencies map[string]map[string]*pb.ServerConfig)
 	return
 }
 
-func (c *configurator) recordError(reason string, err error) {
-	if err == nil {
-		return
-	}
-
-	cerr, ok := err.(errors.ErrObjectContext)
-	if !ok {
-		c.log.
-			WithError(err).
-			Error("Could not record error event due to missing context")
-		return
-	}
-
-	if werr, ok := cerr.WrappedError().(config.ValidationError); !ok {
-		for _, e := range werr {
-			c.recorder.Event(cerr.Object(), api_v1.EventTypeWarning, reason, e.Error())
-		}
+// isDependencyError checks if the error is caused by a missing or inaccessible dependency,
+// the ingress is updated again when the dependency changes
+func isDependencyError(err error) bool {
+	if _, ok := err.(*config.SecretAccessDeniedError); ok {
+		return true
 	}
-
-	c.recorder.Event(
-		cerr.Object(),
-		api_v1.EventTypeWarning,
-		reason,
-		cerr.Error(),
-	)
+	return api_errors.IsNotFound(err)
 }
 
+// serverConfigForIngressKey parses the ingress and its dependencies into server configs,
+// warned is true if warnings have been reported for the ingress
 func (c *configurator) serverConfigForIngressKey(ingressKey string) (
 	ingress *v1beta1.Ingress,
 	servers []*config.Server,
+	warned bool,
 	err error,
 ) {
+	report := func(reason string, err error) {
+		warned = true
+		c.events.Report(reason, err)
+	}
+
 	c.secretWatchlist.Remove(ingressKey)
+	c.configMapWatchlist.Remove(ingressKey)
 	ingress, err = c.ingressAccessor.GetByKey(ingressKey)
 	if err != nil {
 		if api_errors.IsNotFound(err) {
-			return nil, nil, nil
+			return nil, nil, false, nil
 		}
 		return
 	}
@@ -232,24 +365,21 @@ func (c *configurator) serverConfigForIngressKey(ingressKey string) (
 
 	ingressCfg, warning, cfgErr := c.ingParser.Parse(ingress)
 	if warning != nil {
-		c.recordError("Config Warnings", warning)
+		report(ReasonInvalidConfig, warning)
 	}
 	if cfgErr != nil {
 		err = cfgErr
 		return
 	}
+	if snippetWarning := config.ValidateSnippetAnnotations(c.mainConfig, ingressCfg, c.allowSnippetAnnotations); snippetWarning != nil {
+		report(ReasonInvalidAnnotation, snippetWarning)
+	}
 
 	// get basic auth user file
 	var basicAuthUserFile *pb.File
 	if ingressCfg.BasicAuth != "" &&
 		ingressCfg.BasicAuthUserSecret != "" {
-		namespace := ingress.Namespace
-		name := ingressCfg.BasicAuthUserSecret
-		if strings.Contains(ingressCfg.BasicAuthUserSecret, "/") {
-			parts := strings.SplitN(ingressCfg.BasicAuthUserSecret, "/", 2)
-			namespace = parts[0]
-			name = parts[1]
-		}
+		namespace, name := secretReference(ingress.Namespace, ingressCfg.BasicAuthUserSecret)
 		c.secretWatchlist.Add(fmt.Sprintf("%s/%s", namespace, name), ingressKey)
 
 		var secret *api_v1.Secret
@@ -257,20 +387,82 @@ func (c *configurator) serverConfigForIngressKey(ingressKey string) (
 		if err != nil {
 			if !api_errors.IsNotFound(err) {
 				err = errors.WrapInObjectContext(err, ingress)
-				c.recordError("Config Error", err)
+				report(ReasonSecretError, err)
 			} else {
-				c.recordError("Config Error", errors.WrapInObjectContext(err, ingress))
+				report(ReasonSecretNotFound, errors.WrapInObjectContext(
+					fmt.Errorf("%v, basic auth secrets need the label %s=true", err, config.AuthSecretLabel), ingress))
 			}
 			return
 		}
 
+		if err = config.CheckSecretAccess(secret, ingress.Namespace); err != nil {
+			report(ReasonSecretAccessDenied, errors.WrapInObjectContext(err, ingress))
+			return
+		}
+
 		basicAuthUserFile, err = c.basicAuthUserSecretParser.Parse(secret)
 		if err != nil {
-			c.recordError("Config Error", err)
+			report(ReasonInvalidSecret, err)
 			return
 		}
 	}
 
+	// get the CA and client certificate for the backends
+	var proxySSLTrustedCertificate, proxySSLCertificate *pb.File
+	if ingressCfg.ProxySSLSecret != "" {
+		namespace, name := secretReference(ingress.Namespace, ingressCfg.ProxySSLSecret)
+		c.secretWatchlist.Add(fmt.Sprintf("%s/%s", namespace, name), ingressKey)
+
+		var secret *api_v1.Secret
+		secret, err = c.secretAccessor.Get(namespace, name)
+		if err != nil {
+			if !api_errors.IsNotFound(err) {
+				err = errors.WrapInObjectContext(err, ingress)
+				report(ReasonSecretError, err)
+			} else {
+				report(ReasonSecretNotFound, errors.WrapInObjectContext(
+					fmt.Errorf("%v, CA secrets need the type %s or the label %s=true", err, api_v1.SecretTypeTLS, config.CASecretLabel), ingress))
+			}
+			return
+		}
+
+		if err = config.CheckSecretAccess(secret, ingress.Namespace); err != nil {
+			report(ReasonSecretAccessDenied, errors.WrapInObjectContext(err, ingress))
+			return
+		}
+
+		proxySSLTrustedCertificate, proxySSLCertificate, err = c.proxySSLSecretParser.Parse(secret)
+		if err != nil {
+			report(ReasonInvalidSecret, err)
+			return
+		}
+	}
+
+	// get the fastcgi params, the ConfigMap must be in the namespace of the ingress
+	var fastCGIParams map[string]string
+	if ingressCfg.FastCGIParamsConfigMap != "" {
+		c.configMapWatchlist.Add(fmt.Sprintf("%s/%s", ingress.Namespace, ingressCfg.FastCGIParamsConfigMap), ingressKey)
+
+		var cfgm *api_v1.ConfigMap
+		cfgm, err = c.configMapAccessor.Get(ingress.Namespace, ingressCfg.FastCGIParamsConfigMap)
+		if err != nil {
+			if !api_errors.IsNotFound(err) {
+				err = errors.WrapInObjectContext(err, ingress)
+				report(ReasonConfigMapError, err)
+			} else {
+				report(ReasonConfigMapNotFound, errors.WrapInObjectContext(
+					fmt.Errorf("%v, fastcgi params ConfigMaps need the label %s=true", err, config.FastCGIParamsLabel), ingress))
+			}
+			return
+		}
+
+		var paramsWarning error
+		fastCGIParams, paramsWarning = config.ParseFastCGIParams(cfgm)
+		if paramsWarning != nil {
+			report(ReasonInvalidConfigMapKey, paramsWarning)
+		}
+	}
+
 	// get secrets
 	tlsSecrets := map[string]*pb.File{}
 	for _, tls := range ingress.Spec.TLS {
@@ -280,16 +472,17 @@ func (c *configurator) serverConfigForIngressKey(ingressKey string) (
 		if secret, err = c.secretAccessor.Get(ingress.Namespace, tls.SecretName); err != nil {
 			if !api_errors.IsNotFound(err) {
 				err = errors.WrapInObjectContext(err, ingress)
-				c.recordError("Config Error", err)
+				report(ReasonSecretError, err)
 			} else {
-				c.recordError("Config Error", errors.WrapInObjectContext(err, ingress))
+				report(ReasonSecretNotFound, errors.WrapInObjectContext(
+					fmt.Errorf("%v, TLS secrets need the type %s", err, api_v1.SecretTypeTLS), ingress))
 			}
 			return
 		}
 
 		var tlsCert []byte
 		if tlsCert, err = c.tlsSecretParser.Parse(secret); err != nil {
-			c.recordError("Config Error", err)
+			report(ReasonInvalidSecret, err)
 			err = errors.WrapInObjectContext(err, ingress)
 			return
 		}
@@ -347,7 +540,7 @@ func (c *configurator) serverConfigForIngressKey(ingressKey string) (
 	var scWarning error
 	servers, scWarning, err = c.serverConfigParser.Parse(mainCfg, *ingressCfg, tlsSecrets, endpoints)
 	if scWarning != nil {
-		c.recordError("Config Warning", scWarning)
+		report(ReasonInvalidConfig, scWarning)
 	}
 	if basicAuthUserFile != nil {
 		for _, server := range servers {
@@ -361,13 +554,118 @@ func (c *configurator) serverConfigForIngressKey(ingressKey string) (
 			server.Files = append(server.Files, basicAuthUserFile)
 		}
 	}
+	if fastCGIParams != nil {
+		for _, server := range servers {
+			for i := range server.Locations {
+				if server.Locations[i].FastCGI() {
+					server.Locations[i].FastCGIParams = fastCGIParams
+				}
+			}
+		}
+	}
+	if proxySSLTrustedCertificate != nil {
+		for _, server := range servers {
+			for i := range server.Locations {
+				if !server.Locations[i].SSL() {
+					continue
+				}
+				server.Locations[i].ProxySSLTrustedCertificate = proxySSLTrustedCertificate.Name
+				if proxySSLCertificate != nil {
+					server.Locations[i].ProxySSLCertificate = proxySSLCertificate.Name
+				}
+			}
+			server.Files = append(server.Files, proxySSLTrustedCertificate)
+			if proxySSLCertificate != nil {
+				server.Files = append(server.Files, proxySSLCertificate)
+			}
+		}
+	}
 
 	return
 }
 
+// secretReference splits a secret reference in the format "name" or "namespace/name",
+// the namespace of the ingress is used if the reference has no namespace
+func secretReference(namespace, ref string) (string, string) {
+	if strings.Contains(ref, "/") {
+		parts := strings.SplitN(ref, "/", 2)
+		return parts[0], parts[1]
+	}
+	return namespace, ref
+}
+
+// dependencyHosts returns the names of all servers of the dependency map and the given server names,
+// updates of ingresses with overlapping dependency hosts must not run at the same time
+func dependencyHosts(dependencies map[string]map[string]*pb.ServerConfig, serverNames []string) []string {
+	hostMap := map[string]bool{}
+	for _, serverName := range serverNames {
+		hostMap[serverName] = true
+	}
+	for _, serverMap := range dependencies {
+		for serverName := range serverMap {
+			hostMap[serverName] = true
+		}
+	}
+
+	hosts := make([]string, 0, len(hostMap))
+	for host := range hostMap {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// lockDependencies locks all hosts the ingress depends on and returns the dependency map
+// and the locked hosts, which must be released with c.hostLocks.Unlock.
+// Dependencies may change while waiting for the lock, so the dependency map is
+// computed again afterwards until the locked hosts cover all dependencies.
+func (c *configurator) lockDependencies(ingressKey string, serverNames []string) (
+	dependencyMap map[string]map[string]*pb.ServerConfig,
+	hosts []string,
+	err error,
+) {
+	dependencyMap, err = c.dependencyMap(ingressKey, serverNames, nil)
+	if err != nil {
+		return
+	}
+	hosts = dependencyHosts(dependencyMap, serverNames)
+
+	for {
+		c.hostLocks.Lock(hosts)
+		dependencyMap, err = c.dependencyMap(ingressKey, serverNames, nil)
+		if err != nil {
+			c.hostLocks.Unlock(hosts)
+			return nil, nil, err
+		}
+
+		lockedHosts := hosts
+		hosts = dependencyHosts(dependencyMap, serverNames)
+		if reflect.DeepEqual(lockedHosts, hosts) {
+			return
+		}
+		c.hostLocks.Unlock(lockedHosts)
+	}
+}
+
+// reportConflict records the conflict on the winning and the losing ingress
+func (c *configurator) reportConflict(conflict collision.Conflict, warned map[string]bool) {
+	loserReason := ReasonIngressConflict
+	if conflict.Rejected {
+		loserReason = ReasonIngressRejected
+	}
+	c.events.Report(ReasonIngressConflict, errors.WrapInObjectContext(conflict, conflict.Winner))
+	c.events.Report(loserReason, errors.WrapInObjectContext(conflict, conflict.Loser))
+
+	for _, ing := range []*v1beta1.Ingress{conflict.Winner, conflict.Loser} {
+		if key, err := keyFunc(ing); err == nil {
+			warned[key] = true
+		}
+	}
+}
+
 func (c *configurator) IngressUpdated(updatedIngressKey string) (err error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 
 	if c.mainConfig == nil {
 		// no main config
@@ -379,13 +677,15 @@ func (c *configurator) IngressUpdated(updatedIngressKey string) (err error) {
 	}
 
 	updated := map[string]map[string]bool{}
+	// ingresses with reported warnings, the warnings of all other ingresses are cleared
+	warned := map[string]bool{}
 	updatedServerNames := []string{}
 	mergeList := collision.MergeList{}
 
 	// First Ingress/Updated Ingress
-	updatedIngress, updatedServers, err := c.serverConfigForIngressKey(updatedIngressKey)
+	updatedIngress, updatedServers, updatedWarned, err := c.serverConfigForIngressKey(updatedIngressKey)
 	if err != nil {
-		if api_errors.IsNotFound(err) {
+		if isDependencyError(err) {
 			c.log.
 				WithError(err).
 				WithField("ingress", updatedIngressKey).
@@ -399,6 +699,7 @@ func (c *configurator) IngressUpdated(updatedIngressKey string) (err error) {
 		c.log.
 			WithField("ingress", updatedIngressKey).
 			Info("updating")
+		warned[updatedIngressKey] = updatedWarned
 
 		for _, server := range updatedServers {
 			if _, ok := updated[updatedIngressKey]; !ok {
@@ -418,11 +719,12 @@ func (c *configurator) IngressUpdated(updatedIngressKey string) (err error) {
 			Info("deleting")
 	}
 
-	dependencyMap, err := c.dependencyMap(updatedIngressKey, updatedServerNames, nil)
+	dependencyMap, lockedHosts, err := c.lockDependencies(updatedIngressKey, updatedServerNames)
 	if err != nil {
-		c.recordError("Error mapping dependencies", err)
+		c.events.Report(ReasonDependencyError, err)
 		return
 	}
+	defer c.hostLocks.Unlock(lockedHosts)
 
 	if len(dependencyMap) > 1 {
 		dependencyMapLogEntry(dependencyMap).WithField("ingress", updatedIngressKey).Info("has dependencies")
@@ -431,13 +733,14 @@ func (c *configurator) IngressUpdated(updatedIngressKey string) (err error) {
 				continue
 			}
 
-			ing, servers, gerr := c.serverConfigForIngressKey(ingressKey)
+			ing, servers, ingWarned, gerr := c.serverConfigForIngressKey(ingressKey)
 			if gerr != nil {
 				return gerr
 			}
 			if ing == nil {
 				continue
 			}
+			warned[ingressKey] = ingWarned
 
 			for _, server := range servers {
 				if _, ok := updated[ingressKey]; !ok {
@@ -462,10 +765,36 @@ func (c *configurator) IngressUpdated(updatedIngressKey string) (err error) {
 		return err
 	}
 	for _, updated := range mergedServerConfigs {
+		for _, conflict := range updated.Conflicts {
+			c.reportConflict(conflict, warned)
+		}
+		for _, warning := range updated.Warnings {
+			c.events.Report(ReasonInvalidAnnotation, warning)
+			if key, kerr := keyFunc(warning.Object()); kerr == nil {
+				warned[key] = true
+			}
+		}
 		proto, err := c.configurator.RenderServerConfig(&updated)
 		if err != nil {
 			return err
 		}
+		if err = c.validateServerConfig(proto); err != nil {
+			if _, ok := err.(*validation.ConfigError); !ok {
+				return err
+			}
+			// keep the existing server config
+			c.log.
+				WithField("host", updated.Server.Name).
+				WithError(err).
+				Warn("rendered config is invalid, skipping update")
+			for _, ing := range updated.Ingress {
+				c.events.Report(ReasonConfigRejected, errors.WrapInObjectContext(err, ing))
+				if key, kerr := keyFunc(ing); kerr == nil {
+					warned[key] = true
+				}
+			}
+			continue
+		}
 		err = c.scs.Put(proto)
 		if err != nil {
 			return err
@@ -505,5 +834,10 @@ func (c *configurator) IngressUpdated(updatedIngressKey string) (err error) {
 		}
 	}
 
+	for _, ingressConfig := range mergeList {
+		if key, kerr := keyFunc(ingressConfig.Ingress); kerr == nil && !warned[key] {
+			c.events.Clear(ingressConfig.Ingress)
+		}
+	}
 	return nil
 }
@@ -8,12 +8,16 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/thetechnick/nginx-ingress/pkg/apis/nginxingress/v1alpha1"
 	"github.com/thetechnick/nginx-ingress/pkg/collision"
 	"github.com/thetechnick/nginx-ingress/pkg/config"
 	"github.com/thetechnick/nginx-ingress/pkg/errors"
 	"github.com/thetechnick/nginx-ingress/pkg/renderer"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/memory"
 	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
 	"github.com/thetechnick/nginx-ingress/pkg/test"
+	"github.com/thetechnick/nginx-ingress/pkg/validation"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	api_v1 "k8s.io/client-go/pkg/api/v1"
@@ -38,6 +42,15 @@ func (m *ConfigMapParserMock) Parse(cfgm *api_v1.ConfigMap) (*config.GlobalConfi
 	return args.Get(0).(*config.GlobalConfig), args.Error(1)
 }
 
+type NginxIngressConfigParserMock struct {
+	mock.Mock
+}
+
+func (m *NginxIngressConfigParserMock) Parse(cfg *v1alpha1.NginxIngressConfig) (*config.GlobalConfig, error) {
+	args := m.Called(cfg)
+	return args.Get(0).(*config.GlobalConfig), args.Error(1)
+}
+
 type CollisionHandlerMock struct {
 	mock.Mock
 }
@@ -65,6 +78,15 @@ func (m *SecretAccessorMock) Get(namespace, name string) (*api_v1.Secret, error)
 	return args.Get(0).(*api_v1.Secret), args.Error(1)
 }
 
+type ConfigMapAccessorMock struct {
+	mock.Mock
+}
+
+func (m *ConfigMapAccessorMock) Get(namespace, name string) (*api_v1.ConfigMap, error) {
+	args := m.Called(namespace, name)
+	return args.Get(0).(*api_v1.ConfigMap), args.Error(1)
+}
+
 type EndpointsAccessorMock struct {
 	mock.Mock
 }
@@ -88,6 +110,11 @@ func (m *RendererMock) RenderServerConfig(mergedConfig *collision.MergedIngressC
 	return args.Get(0).(*pb.ServerConfig), args.Error(1)
 }
 
+func (m *RendererMock) UpdateTemplates(mainConfigTemplate, ingressTemplate string) error {
+	args := m.Called(mainConfigTemplate, ingressTemplate)
+	return args.Error(0)
+}
+
 type SecretParserMock struct {
 	mock.Mock
 }
@@ -120,6 +147,15 @@ func (m *ServerConfigParserMock) Parse(
 	return args.Get(0).([]*config.Server), args.Error(1), args.Error(2)
 }
 
+type ValidatorMock struct {
+	mock.Mock
+}
+
+func (m *ValidatorMock) ValidateServerConfig(mainConfig *pb.MainConfig, serverConfig *pb.ServerConfig) error {
+	args := m.Called(mainConfig, serverConfig)
+	return args.Error(0)
+}
+
 type RecorderMock struct {
 	mock.Mock
 }
@@ -142,16 +178,19 @@ func TestConfigurator(t *testing.T) {
 
 	var ingressAccessor *IngressAccessorMock
 	var secretAccessor *SecretAccessorMock
+	var configMapAccessor *ConfigMapAccessorMock
 	var endpointsAccessor *EndpointsAccessorMock
 
 	var tlsSecretParser *SecretParserMock
 	var configMapParser *ConfigMapParserMock
+	var nginxConfigParser *NginxIngressConfigParserMock
 	var ingressConfigParser *IngressConfigParserMock
 	var serverConfigParser *ServerConfigParserMock
 
 	var collisionHandler *CollisionHandlerMock
 	var r *RendererMock
 	var recorder *RecorderMock
+	var validator *ValidatorMock
 	var c *configurator
 
 	ingress1 := v1beta1.Ingress{
@@ -189,20 +228,31 @@ func TestConfigurator(t *testing.T) {
 		Data: map[string]string{},
 	}
 
+	nic := v1alpha1.NginxIngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "nic1",
+			Namespace:  "default",
+			Generation: 2,
+		},
+	}
+
 	beforeEach := func() {
 		serverConfigStorage = &test.ServerConfigStorageMock{}
 		mainConfigStorage = &test.MainConfigStorageMock{}
 		ingressAccessor = &IngressAccessorMock{}
 		secretAccessor = &SecretAccessorMock{}
+		configMapAccessor = &ConfigMapAccessorMock{}
 		endpointsAccessor = &EndpointsAccessorMock{}
 		tlsSecretParser = &SecretParserMock{}
 		serverConfigParser = &ServerConfigParserMock{}
 		ingressConfigParser = &IngressConfigParserMock{}
 
 		configMapParser = &ConfigMapParserMock{}
+		nginxConfigParser = &NginxIngressConfigParserMock{}
 		collisionHandler = &CollisionHandlerMock{}
 		r = &RendererMock{}
 		recorder = &RecorderMock{}
+		validator = &ValidatorMock{}
 		logger := log.New()
 		logger.SetLevel(log.DebugLevel)
 
@@ -212,18 +262,22 @@ func TestConfigurator(t *testing.T) {
 
 			ingressAccessor:   ingressAccessor,
 			secretAccessor:    secretAccessor,
+			configMapAccessor: configMapAccessor,
 			endpointsAccessor: endpointsAccessor,
 
-			secretWatchlist: NewWatchlist(),
+			secretWatchlist:    NewWatchlist(),
+			configMapWatchlist: NewWatchlist(),
 
 			configMapParser:    configMapParser,
+			nginxConfigParser:  nginxConfigParser,
 			tlsSecretParser:    tlsSecretParser,
 			ingParser:          ingressConfigParser,
 			serverConfigParser: serverConfigParser,
 
 			ch:           collisionHandler,
 			configurator: r,
-			recorder:     recorder,
+			events:       NewEventReporter(recorder),
+			validator:    validator,
 			log:          logger.WithField("test", "TestConfigurator"),
 		}
 	}
@@ -236,6 +290,17 @@ func TestConfigurator(t *testing.T) {
 		assert.NoError(err)
 	})
 
+	t.Run("IngressDeleted forgets the reported warnings", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		c.events.Report(ReasonSecretNotFound, errors.WrapInObjectContext(fmt.Errorf("secret not found"), &ingress1))
+		err := c.IngressDeleted("default/ing1")
+		assert.NoError(err)
+		assert.Empty(c.events.(*eventReporter).reported)
+	})
+
 	t.Run("IngressUpdated", func(t *testing.T) {
 		beforeEach()
 		assert := assert.New(t)
@@ -307,6 +372,419 @@ func TestConfigurator(t *testing.T) {
 		serverConfigStorage.AssertCalled(t, "Put", rendered)
 	})
 
+	t.Run("IngressUpdated should record conflicts on both ingresses", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		c.mainConfig = config.NewDefaultConfig()
+		server1 := &config.Server{
+			Name: "one.example.com",
+		}
+		servers := []*config.Server{server1}
+		mergeList := collision.MergeList{
+			collision.IngressConfig{
+				Ingress: ingEx1.Ingress,
+				Servers: servers,
+			},
+			collision.IngressConfig{
+				Ingress: ingEx2.Ingress,
+				Servers: servers,
+			},
+		}
+		conflict := collision.Conflict{
+			Host:   "one.example.com",
+			Kind:   collision.ConflictLocation,
+			Name:   "/",
+			Winner: &ingress2,
+			Loser:  &ingress1,
+		}
+		mergedList := []collision.MergedIngressConfig{
+			collision.MergedIngressConfig{
+				Server:    server1,
+				Ingress:   []*v1beta1.Ingress{ingEx1.Ingress, ingEx2.Ingress},
+				Conflicts: []collision.Conflict{conflict},
+			},
+		}
+		sc1 := &pb.ServerConfig{
+			Meta: map[string]string{
+				"default/ing1": "",
+				"default/ing2": "",
+			},
+			Name: "one.example.com",
+		}
+		rendered := &pb.ServerConfig{
+			Name: "one.example.com",
+		}
+
+		ingressAccessor.On("GetByKey", "default/ing1").Return(&ingress1, nil)
+		ingressAccessor.On("GetByKey", "default/ing2").Return(&ingress2, nil)
+		ingressConfigParser.On("Parse", &ingress1).Return(&config.IngressConfig{
+			Ingress: &ingress1,
+		}, nil, nil)
+		ingressConfigParser.On("Parse", &ingress2).Return(&config.IngressConfig{
+			Ingress: &ingress2,
+		}, nil, nil)
+		serverConfigStorage.On("Get", "one.example.com").Return(sc1, nil)
+		serverConfigStorage.On("ByIngressKey", mock.Anything).Return([]*pb.ServerConfig{sc1}, nil)
+		serverConfigParser.On("Parse", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(servers, nil, nil)
+		collisionHandler.On("Resolve", mergeList).Return(mergedList, nil)
+		r.On("RenderServerConfig", &mergedList[0]).Return(rendered, nil)
+		serverConfigStorage.On("Put", rendered).Return(nil)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		err := c.IngressUpdated("default/ing1")
+		assert.NoError(err)
+		recorder.AssertCalled(t, "Event", &ingress2, api_v1.EventTypeWarning, ReasonIngressConflict, conflict.Error())
+		recorder.AssertCalled(t, "Event", &ingress1, api_v1.EventTypeWarning, ReasonIngressConflict, conflict.Error())
+		recorder.AssertNotCalled(t, "Event", mock.Anything, api_v1.EventTypeNormal, ReasonWarningsResolved, mock.Anything)
+	})
+
+	t.Run("IngressUpdated should record warnings of the collision handler", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		c.mainConfig = config.NewDefaultConfig()
+		server1 := &config.Server{
+			Name: "one.example.com",
+		}
+		servers := []*config.Server{server1}
+		mergeList := collision.MergeList{
+			collision.IngressConfig{
+				Ingress: ingEx1.Ingress,
+				Servers: servers,
+			},
+		}
+		warning := errors.WrapInObjectContext(&config.IngressAnnotationError{
+			Annotation:      collision.PolicyAnnotation,
+			ValidationError: fmt.Errorf("test error"),
+		}, &ingress1)
+		mergedList := []collision.MergedIngressConfig{
+			collision.MergedIngressConfig{
+				Server:   server1,
+				Ingress:  []*v1beta1.Ingress{ingEx1.Ingress},
+				Warnings: []errors.ErrObjectContext{warning},
+			},
+		}
+		rendered := &pb.ServerConfig{
+			Name: "one.example.com",
+		}
+
+		ingressAccessor.On("GetByKey", "default/ing1").Return(&ingress1, nil)
+		ingressConfigParser.On("Parse", &ingress1).Return(&config.IngressConfig{
+			Ingress: &ingress1,
+		}, nil, nil)
+		serverConfigStorage.On("Get", "one.example.com").Return((*pb.ServerConfig)(nil), nil)
+		serverConfigStorage.On("ByIngressKey", mock.Anything).Return([]*pb.ServerConfig{}, nil)
+		serverConfigParser.On("Parse", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(servers, nil, nil)
+		collisionHandler.On("Resolve", mergeList).Return(mergedList, nil)
+		r.On("RenderServerConfig", &mergedList[0]).Return(rendered, nil)
+		serverConfigStorage.On("Put", rendered).Return(nil)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		err := c.IngressUpdated("default/ing1")
+		assert.NoError(err)
+		recorder.AssertCalled(t, "Event", &ingress1, api_v1.EventTypeWarning, ReasonInvalidAnnotation, warning.WrappedError().Error())
+		recorder.AssertNotCalled(t, "Event", mock.Anything, api_v1.EventTypeNormal, ReasonWarningsResolved, mock.Anything)
+	})
+
+	t.Run("IngressUpdated should not publish invalid server configs", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		c.mainConfig = config.NewDefaultConfig()
+		c.renderedMainConfig = &pb.MainConfig{}
+		server1 := &config.Server{
+			Name: "one.example.com",
+		}
+		servers := []*config.Server{server1}
+		mergeList := collision.MergeList{
+			collision.IngressConfig{
+				Ingress: ingEx1.Ingress,
+				Servers: servers,
+			},
+		}
+		mergedList := []collision.MergedIngressConfig{
+			collision.MergedIngressConfig{
+				Server:  server1,
+				Ingress: []*v1beta1.Ingress{ingEx1.Ingress},
+			},
+		}
+		existing := &pb.ServerConfig{
+			Meta: map[string]string{
+				"default/ing1": "",
+			},
+			Name: "one.example.com",
+		}
+		rendered := &pb.ServerConfig{
+			Name: "one.example.com",
+		}
+		verr := &validation.ConfigError{ServerName: "one.example.com", Output: "nginx: [emerg] test"}
+
+		ingressAccessor.On("GetByKey", "default/ing1").Return(&ingress1, nil)
+		ingressConfigParser.On("Parse", &ingress1).Return(&config.IngressConfig{
+			Ingress: &ingress1,
+		}, nil, nil)
+		serverConfigStorage.On("Get", "one.example.com").Return(existing, nil)
+		serverConfigStorage.On("ByIngressKey", "default/ing1").Return([]*pb.ServerConfig{existing}, nil)
+		serverConfigParser.On("Parse", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(servers, nil, nil)
+		collisionHandler.On("Resolve", mergeList).Return(mergedList, nil)
+		r.On("RenderServerConfig", &mergedList[0]).Return(rendered, nil)
+		validator.On("ValidateServerConfig", c.renderedMainConfig, rendered).Return(verr)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		err := c.IngressUpdated("default/ing1")
+		assert.NoError(err)
+		serverConfigStorage.AssertNotCalled(t, "Put", mock.Anything)
+		serverConfigStorage.AssertNotCalled(t, "Delete", mock.Anything)
+		recorder.AssertCalled(t, "Event", &ingress1, api_v1.EventTypeWarning, ReasonConfigRejected, verr.Error())
+	})
+
+	t.Run("IngressDeleted hands the host over to rejected ingresses", func(t *testing.T) {
+		oldestWins, err := collision.NewCollisionHandler(collision.PolicyOldestWins, false)
+		if !assert.NoError(t, err) {
+			return
+		}
+		server := func(upstream, address string) *config.Server {
+			return &config.Server{
+				Name: "one.example.com",
+				Locations: []config.Location{{
+					Path: "/",
+					Upstream: config.Upstream{
+						Name:            upstream,
+						UpstreamServers: []config.UpstreamServer{{Address: address, Port: "80"}},
+					},
+				}},
+			}
+		}
+		ingressConfig := func(ing *v1beta1.Ingress) interface{} {
+			return mock.MatchedBy(func(ingCfg config.IngressConfig) bool { return ingCfg.Ingress == ing })
+		}
+
+		for name, ch := range map[string]collision.Handler{
+			"oldest-wins":       oldestWins,
+			"strict-collisions": collision.NewStrictCollisionHandler(),
+		} {
+			t.Run(name, func(t *testing.T) {
+				beforeEach()
+				assert := assert.New(t)
+				scs := memory.NewServerConfigStorage()
+				c.scs = scs
+				c.ch = ch
+				c.configurator = renderer.NewRenderer()
+				c.mainConfig = config.NewDefaultConfig()
+
+				ingressAccessor.On("GetByKey", "default/ing1").Return(&ingress1, nil).Twice()
+				ingressAccessor.On("GetByKey", "default/ing1").Return((*v1beta1.Ingress)(nil), api_errors.NewNotFound(v1beta1.Resource("ingress"), "ing1"))
+				ingressAccessor.On("GetByKey", "default/ing2").Return(&ingress2, nil)
+				ingressConfigParser.On("Parse", &ingress1).Return(&config.IngressConfig{Ingress: &ingress1}, nil, nil)
+				ingressConfigParser.On("Parse", &ingress2).Return(&config.IngressConfig{Ingress: &ingress2}, nil, nil)
+				serverConfigParser.On("Parse", mock.Anything, ingressConfig(&ingress1), mock.Anything, mock.Anything).
+					Return([]*config.Server{server("default-ing1-one", "10.0.0.1")}, nil, nil)
+				serverConfigParser.On("Parse", mock.Anything, ingressConfig(&ingress2), mock.Anything, mock.Anything).
+					Return([]*config.Server{server("default-ing2-one", "10.0.0.2")}, nil, nil)
+				recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+				assert.NoError(c.IngressUpdated("default/ing1"))
+				assert.NoError(c.IngressUpdated("default/ing2"))
+				sc, _ := scs.Get("one.example.com")
+				if assert.NotNil(sc) {
+					assert.Equal(map[string]string{"default/ing1": "", "default/ing2": ""}, sc.Meta)
+					assert.Contains(string(sc.Config), "default-ing1-one")
+				}
+
+				assert.NoError(c.IngressDeleted("default/ing1"))
+				sc, _ = scs.Get("one.example.com")
+				if assert.NotNil(sc, "host should be handed over to default/ing2") {
+					assert.Equal(map[string]string{"default/ing2": ""}, sc.Meta)
+					assert.Contains(string(sc.Config), "default-ing2-one")
+				}
+			})
+		}
+	})
+
+	t.Run("IngressUpdated should deny cross-namespace basic auth secrets without grant", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		c.mainConfig = config.NewDefaultConfig()
+		secret := &api_v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "users",
+				Namespace: "other",
+			},
+		}
+
+		ingressAccessor.On("GetByKey", "default/ing1").Return(&ingress1, nil)
+		ingressConfigParser.On("Parse", &ingress1).Return(&config.IngressConfig{
+			Ingress:             &ingress1,
+			BasicAuth:           "restricted",
+			BasicAuthUserSecret: "other/users",
+		}, nil, nil)
+		secretAccessor.On("Get", "other", "users").Return(secret, nil)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		err := c.IngressUpdated("default/ing1")
+		assert.NoError(err)
+		recorder.AssertCalled(t, "Event", &ingress1, api_v1.EventTypeWarning, ReasonSecretAccessDenied, mock.Anything)
+		serverConfigStorage.AssertNotCalled(t, "Put", mock.Anything)
+	})
+
+	t.Run("IngressUpdated should add the proxy SSL certificates to SSL locations", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		c.mainConfig = config.NewDefaultConfig()
+		c.proxySSLSecretParser = config.NewProxySSLSecretParser()
+		server1 := &config.Server{
+			Name: "one.example.com",
+			Locations: []config.Location{
+				{Path: "/", BackendProtocol: config.BackendProtocolHTTPS},
+				{Path: "/plain", BackendProtocol: config.BackendProtocolHTTP},
+			},
+		}
+		servers := []*config.Server{server1}
+		mergeList := collision.MergeList{
+			collision.IngressConfig{
+				Ingress: ingEx1.Ingress,
+				Servers: servers,
+			},
+		}
+		mergedList := []collision.MergedIngressConfig{
+			collision.MergedIngressConfig{
+				Server:  server1,
+				Ingress: []*v1beta1.Ingress{ingEx1.Ingress},
+			},
+		}
+		existing := &pb.ServerConfig{
+			Meta: map[string]string{
+				"default/ing1": "",
+			},
+			Name: "one.example.com",
+		}
+		rendered := &pb.ServerConfig{
+			Name: "one.example.com",
+		}
+		secret := &api_v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "backend-ca",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{config.CACertKey: []byte("ca")},
+		}
+
+		ingressAccessor.On("GetByKey", "default/ing1").Return(&ingress1, nil)
+		ingressConfigParser.On("Parse", &ingress1).Return(&config.IngressConfig{
+			Ingress:        &ingress1,
+			ProxySSLSecret: "backend-ca",
+		}, nil, nil)
+		secretAccessor.On("Get", "default", "backend-ca").Return(secret, nil)
+		serverConfigStorage.On("Get", "one.example.com").Return(existing, nil)
+		serverConfigStorage.On("ByIngressKey", "default/ing1").Return([]*pb.ServerConfig{existing}, nil)
+		serverConfigParser.On("Parse", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(servers, nil, nil)
+		collisionHandler.On("Resolve", mergeList).Return(mergedList, nil)
+		r.On("RenderServerConfig", &mergedList[0]).Return(rendered, nil)
+		serverConfigStorage.On("Put", rendered).Return(nil)
+
+		err := c.IngressUpdated("default/ing1")
+		assert.NoError(err)
+		assert.Equal("/etc/nginx/ssl/default-backend-ca-ca.pem", server1.Locations[0].ProxySSLTrustedCertificate)
+		assert.Empty(server1.Locations[1].ProxySSLTrustedCertificate)
+		if assert.Len(server1.Files, 1) {
+			assert.Equal([]byte("ca"), server1.Files[0].Content)
+		}
+		assert.Equal([]string{"default/ing1"}, c.secretWatchlist.Watchers("default/backend-ca"))
+	})
+
+	t.Run("IngressUpdated should add the fastcgi params to FastCGI locations", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		c.mainConfig = config.NewDefaultConfig()
+		server1 := &config.Server{
+			Name: "one.example.com",
+			Locations: []config.Location{
+				{Path: "/", BackendProtocol: config.BackendProtocolFCGI},
+				{Path: "/static", BackendProtocol: config.BackendProtocolHTTP},
+			},
+		}
+		servers := []*config.Server{server1}
+		mergeList := collision.MergeList{
+			collision.IngressConfig{
+				Ingress: ingEx1.Ingress,
+				Servers: servers,
+			},
+		}
+		mergedList := []collision.MergedIngressConfig{
+			collision.MergedIngressConfig{
+				Server:  server1,
+				Ingress: []*v1beta1.Ingress{ingEx1.Ingress},
+			},
+		}
+		existing := &pb.ServerConfig{
+			Meta: map[string]string{
+				"default/ing1": "",
+			},
+			Name: "one.example.com",
+		}
+		rendered := &pb.ServerConfig{
+			Name: "one.example.com",
+		}
+		params := &api_v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "php-params",
+				Namespace: "default",
+			},
+			Data: map[string]string{
+				"SCRIPT_FILENAME": "/var/www/html$fastcgi_script_name",
+				"invalid name":    "value",
+			},
+		}
+
+		ingressAccessor.On("GetByKey", "default/ing1").Return(&ingress1, nil)
+		ingressConfigParser.On("Parse", &ingress1).Return(&config.IngressConfig{
+			Ingress:                &ingress1,
+			FastCGIParamsConfigMap: "php-params",
+		}, nil, nil)
+		configMapAccessor.On("Get", "default", "php-params").Return(params, nil)
+		serverConfigStorage.On("Get", "one.example.com").Return(existing, nil)
+		serverConfigStorage.On("ByIngressKey", "default/ing1").Return([]*pb.ServerConfig{existing}, nil)
+		serverConfigParser.On("Parse", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(servers, nil, nil)
+		collisionHandler.On("Resolve", mergeList).Return(mergedList, nil)
+		r.On("RenderServerConfig", &mergedList[0]).Return(rendered, nil)
+		serverConfigStorage.On("Put", rendered).Return(nil)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		err := c.IngressUpdated("default/ing1")
+		assert.NoError(err)
+		assert.Equal(map[string]string{"SCRIPT_FILENAME": "/var/www/html$fastcgi_script_name"}, server1.Locations[0].FastCGIParams)
+		assert.Nil(server1.Locations[1].FastCGIParams)
+		recorder.AssertCalled(t, "Event", params, api_v1.EventTypeWarning, ReasonInvalidConfigMapKey, mock.Anything)
+		assert.Equal([]string{"default/ing1"}, c.configMapWatchlist.Watchers("default/php-params"))
+		serverConfigStorage.AssertCalled(t, "Put", rendered)
+	})
+
+	t.Run("IngressUpdated should wait for missing fastcgi params ConfigMaps", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		c.mainConfig = config.NewDefaultConfig()
+		ingressAccessor.On("GetByKey", "default/ing1").Return(&ingress1, nil)
+		ingressConfigParser.On("Parse", &ingress1).Return(&config.IngressConfig{
+			Ingress:                &ingress1,
+			FastCGIParamsConfigMap: "php-params",
+		}, nil, nil)
+		configMapAccessor.On("Get", "default", "php-params").Return(
+			(*api_v1.ConfigMap)(nil), api_errors.NewNotFound(api_v1.Resource("configmaps"), "php-params"))
+		serverConfigStorage.On("ByIngressKey", "default/ing1").Return([]*pb.ServerConfig{}, nil)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		err := c.IngressUpdated("default/ing1")
+		assert.NoError(err)
+		recorder.AssertCalled(t, "Event", &ingress1, api_v1.EventTypeWarning, ReasonConfigMapNotFound, mock.Anything)
+		assert.Equal([]string{"default/ing1"}, c.configMapWatchlist.Watchers("default/php-params"))
+		serverConfigStorage.AssertNotCalled(t, "Put", mock.Anything)
+	})
+
 	// ConfigUpdated
 	t.Run("ConfigUpdated", func(t *testing.T) {
 		beforeEach()
@@ -344,6 +822,127 @@ func TestConfigurator(t *testing.T) {
 		configMapParser.AssertCalled(t, "Parse", &cfgm)
 		r.AssertCalled(t, "RenderMainConfig", mctd)
 		mainConfigStorage.AssertCalled(t, "Put", mc)
-		recorder.AssertCalled(t, "Event", &cfgm, api_v1.EventTypeWarning, "Config Error", mock.Anything)
+		recorder.AssertCalled(t, "Event", &cfgm, api_v1.EventTypeWarning, ReasonInvalidConfig, "test error")
+	})
+
+	t.Run("ConfigUpdated should prefer the templates of the cfgm over the template files", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		nc := config.NewDefaultConfig()
+		nc.IngressTemplate = "# ingress from cfgm"
+		mc := &pb.MainConfig{}
+		configMapParser.On("Parse", &cfgm).Return(nc, nil)
+		r.On("UpdateTemplates", "# main from file", "# ingress from file").Return(nil)
+		r.On("UpdateTemplates", "# main from file", "# ingress from cfgm").Return(nil)
+		r.On("RenderMainConfig", mock.Anything).Return(mc, nil)
+		mainConfigStorage.On("Put", mc).Return(nil)
+
+		assert.NoError(c.TemplatesUpdated("# main from file", "# ingress from file"))
+		assert.NoError(c.ConfigUpdated(&cfgm))
+		r.AssertCalled(t, "UpdateTemplates", "# main from file", "# ingress from cfgm")
+		mainConfigStorage.AssertNumberOfCalls(t, "Put", 1)
+
+		// unchanged templates are not parsed again
+		assert.NoError(c.ConfigUpdated(&cfgm))
+		r.AssertNumberOfCalls(t, "UpdateTemplates", 2)
+	})
+
+	t.Run("ConfigUpdated should record template errors on the cfgm object", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		nc := config.NewDefaultConfig()
+		nc.MainTemplate = "{{"
+		mc := &pb.MainConfig{}
+		terr := &renderer.TemplateError{Template: renderer.MainConfigTemplateName, Err: fmt.Errorf("test error")}
+		configMapParser.On("Parse", &cfgm).Return(nc, nil)
+		r.On("UpdateTemplates", "{{", "").Return(terr)
+		r.On("RenderMainConfig", mock.Anything).Return(mc, nil)
+		mainConfigStorage.On("Put", mc).Return(nil)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		err := c.ConfigUpdated(&cfgm)
+		assert.NoError(err)
+		mainConfigStorage.AssertCalled(t, "Put", mc)
+		recorder.AssertCalled(t, "Event", &cfgm, api_v1.EventTypeWarning, ReasonInvalidTemplate, terr.Error())
+	})
+
+	// NginxConfigUpdated
+	t.Run("NginxConfigUpdated", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		nc := config.NewDefaultConfig()
+		mctd := renderer.MainConfigTemplateDataFromIngressConfig(nc)
+		mc := &pb.MainConfig{}
+		nginxConfigParser.On("Parse", &nic).Return(nc, nil)
+		r.On("RenderMainConfig", mock.Anything).Return(mc, nil)
+		mainConfigStorage.On("Put", mc).Return(nil)
+
+		warning, err := c.NginxConfigUpdated(&nic)
+		assert.NoError(warning)
+		assert.NoError(err)
+		nginxConfigParser.AssertCalled(t, "Parse", &nic)
+		r.AssertCalled(t, "RenderMainConfig", mctd)
+		mainConfigStorage.AssertCalled(t, "Put", mc)
+		assert.Equal(nc, c.mainConfig)
+	})
+
+	t.Run("NginxConfigUpdated should return and record config errors", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		nc := config.NewDefaultConfig()
+		mc := &pb.MainConfig{}
+		e := errors.WrapInObjectContext(config.ValidationError([]error{fmt.Errorf("test error")}), &nic)
+		nginxConfigParser.On("Parse", &nic).Return(nc, e)
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		r.On("RenderMainConfig", mock.Anything).Return(mc, nil)
+		mainConfigStorage.On("Put", mc).Return(nil)
+
+		warning, err := c.NginxConfigUpdated(&nic)
+		assert.Equal(e, warning)
+		assert.NoError(err)
+		mainConfigStorage.AssertCalled(t, "Put", mc)
+		recorder.AssertCalled(t, "Event", &nic, api_v1.EventTypeWarning, ReasonInvalidConfig, "test error")
+	})
+
+	t.Run("NginxConfigDeleted falls back to the ConfigMap", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		nc := config.NewDefaultConfig()
+		nc.HTTP2 = true
+		mc := &pb.MainConfig{}
+		configMapParser.On("Parse", &cfgm).Return(nc, nil)
+		r.On("RenderMainConfig", mock.Anything).Return(mc, nil)
+		mainConfigStorage.On("Put", mc).Return(nil)
+
+		assert.NoError(c.NginxConfigDeleted("default/nic1", &cfgm))
+		configMapParser.AssertCalled(t, "Parse", &cfgm)
+		mainConfigStorage.AssertCalled(t, "Put", mc)
+		assert.Equal(nc, c.mainConfig)
+	})
+
+	t.Run("NginxConfigDeleted falls back to the default config", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+
+		nc := config.NewDefaultConfig()
+		nc.HTTP2 = true
+		c.mainConfig = nc
+		c.mainConfigObject = &nic
+		mc := &pb.MainConfig{}
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		r.On("RenderMainConfig", renderer.MainConfigTemplateDataFromIngressConfig(config.NewDefaultConfig())).Return(mc, nil)
+		mainConfigStorage.On("Put", mc).Return(nil)
+
+		c.events.Report(ReasonInvalidConfig, errors.WrapInObjectContext(fmt.Errorf("test error"), &nic))
+		assert.NoError(c.NginxConfigDeleted("default/nic1", nil))
+		mainConfigStorage.AssertCalled(t, "Put", mc)
+		assert.Equal(config.NewDefaultConfig(), c.mainConfig)
+		assert.Nil(c.mainConfigObject)
+		assert.Empty(c.events.(*eventReporter).reported)
 	})
 }
@@ -22,19 +22,23 @@ import (
 	"strings"
 	"time"
 
+	"github.com/thetechnick/nginx-ingress/pkg/apis/nginxingress/v1alpha1"
+	"github.com/thetechnick/nginx-ingress/pkg/collision"
 	"github.com/thetechnick/nginx-ingress/pkg/config"
 	"github.com/thetechnick/nginx-ingress/pkg/errors"
 	"github.com/thetechnick/nginx-ingress/pkg/storage"
+	"github.com/thetechnick/nginx-ingress/pkg/validation"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 
 	log "github.com/sirupsen/logrus"
-	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
 	core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	api_v1 "k8s.io/client-go/pkg/api/v1"
 	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
@@ -49,26 +53,49 @@ const (
 // LoadBalancerController watches Kubernetes API and
 // reconfigures NGINX via Controller when needed
 type LoadBalancerController struct {
-	client           kubernetes.Interface
-	ingController    cache.Controller
-	svcController    cache.Controller
-	endpController   cache.Controller
-	cfgmController   cache.Controller
-	secretController cache.Controller
+	client         kubernetes.Interface
+	ingController  cache.Controller
+	svcController  cache.Controller
+	endpController cache.Controller
+	cfgmController cache.Controller
+	nicController  cache.Controller
 
-	secretWatchlist Watchlist
+	tlsSecretController  cache.Controller
+	authSecretController cache.Controller
+	caSecretController   cache.Controller
+
+	fastCGIParamsController cache.Controller
+
+	secretWatchlist    Watchlist
+	configMapWatchlist Watchlist
 
 	ingLister            StoreToIngressLister
 	svcLister            cache.Store
-	secretLister         cache.Store
+	tlsSecretLister      cache.Store
+	authSecretLister     cache.Store
+	caSecretLister       cache.Store
+	fastCGIParamsLister  cache.Store
 	endpLister           StoreToEndpointLister
 	cfgmLister           StoreToConfigMapLister
+	nicLister            cache.Store
 	ingQueue             TaskQueue
+	ingressWorkers       int
+	recorder             record.EventRecorder
 	cfgmQueue            TaskQueue
+	nicQueue             TaskQueue
 	stopCh               chan struct{}
 	watchNginxConfigMaps bool
+	watchNginxConfig     bool
+	// keys of the -nginx-configmaps ConfigMap and the -nginx-config NginxIngressConfig,
+	// the ConfigMap is only used while the NginxIngressConfig does not exist
+	nginxConfigMapsKey string
+	nginxConfigKey     string
+
+	nicClient rest.Interface
 
 	configurator Configurator
+	// templateWatcher reloads the template files, nil if the embedded templates are used
+	templateWatcher *templateWatcher
 }
 
 var keyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
@@ -80,6 +107,15 @@ func NewLoadBalancerController(
 	namespace string,
 	selector labels.Selector,
 	nginxConfigMaps string,
+	nicClient rest.Interface,
+	nginxConfig string,
+	validator validation.Validator,
+	allowSnippetAnnotations bool,
+	collisionHandler collision.Handler,
+	mainTemplateFile string,
+	ingressTemplateFile string,
+	ingressWorkers int,
+	maxRetries int,
 	mcs storage.MainConfigStorage,
 	scs storage.ServerConfigStorage,
 ) (*LoadBalancerController, error) {
@@ -88,24 +124,33 @@ func NewLoadBalancerController(
 		Interface: kubeClient.Core().Events(""),
 	})
 	lbc := LoadBalancerController{
-		client:          kubeClient,
-		stopCh:          make(chan struct{}),
-		secretWatchlist: NewWatchlist(),
+		client:             kubeClient,
+		nicClient:          nicClient,
+		stopCh:             make(chan struct{}),
+		secretWatchlist:    NewWatchlist(),
+		configMapWatchlist: NewWatchlist(),
+		ingressWorkers:     ingressWorkers,
+		recorder:           eventBroadcaster.NewRecorder(scheme.Scheme, api_v1.EventSource{Component: "ingress-controller"}),
 	}
 
 	lbc.configurator = NewConfigurator(
 		&ingressAccessorFuncs{lbc.getIngressByKey},
 		&secretAccessorFuncs{lbc.getSecret},
+		&configMapAccessorFuncs{lbc.getFastCGIParamsConfigMap},
 		&endpointsAccessorFuncs{lbc.getEndpointsForIngressBackend},
 
 		lbc.secretWatchlist,
+		lbc.configMapWatchlist,
 
-		eventBroadcaster.NewRecorder(scheme.Scheme, api_v1.EventSource{Component: "ingress-controller"}),
+		lbc.recorder,
+		validator,
+		allowSnippetAnnotations,
+		collisionHandler,
 		mcs,
 		scs,
 	)
 
-	lbc.ingQueue = NewTaskQueue(lbc.syncIng, log.WithField("module", "IngressTaskQueue"))
+	lbc.ingQueue = NewTaskQueue("IngressTaskQueue", lbc.syncIng, maxRetries, lbc.ingressSyncFailed)
 
 	ingHandlers := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -114,7 +159,7 @@ func NewLoadBalancerController(
 				log.
 					WithField("namespace", addIng.Namespace).
 					WithField("name", addIng.Name).
-					Info("Ignoring Ingress based on Annotation: %v", ingressClassKey)
+					Infof("Ignoring Ingress based on Annotation: %v", ingressClassKey)
 				return
 			}
 			log.
@@ -164,9 +209,10 @@ func NewLoadBalancerController(
 			}
 		},
 	}
-	lbc.ingLister.Store, lbc.ingController = cache.NewInformer(
+	lbc.ingLister.Indexer, lbc.ingController = cache.NewIndexerInformer(
 		NewListWatchFromClient(lbc.client.Extensions().RESTClient(), "ingresses", namespace, selector),
-		&extensions.Ingress{}, resyncPeriod, ingHandlers)
+		&extensions.Ingress{}, resyncPeriod, ingHandlers,
+		cache.Indexers{ingressServiceIndex: ingressServiceIndexFunc})
 
 	secretHandlers := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -200,14 +246,74 @@ func NewLoadBalancerController(
 			}
 		},
 	}
-	lbc.secretLister, lbc.secretController = cache.NewInformer(
+	// only secrets consumed by the controller are cached:
+	// TLS secrets, basic auth secrets with the auth secret label and CA secrets with the CA secret label
+	lbc.tlsSecretLister, lbc.tlsSecretController = cache.NewInformer(
 		cache.NewListWatchFromClient(
 			lbc.client.Core().RESTClient(),
 			"secrets",
 			namespace,
-			fields.Everything(),
+			fields.OneTermEqualSelector("type", string(api_v1.SecretTypeTLS)),
+		),
+		&api_v1.Secret{}, resyncPeriod, secretHandlers)
+	lbc.authSecretLister, lbc.authSecretController = cache.NewInformer(
+		NewListWatchFromClient(
+			lbc.client.Core().RESTClient(),
+			"secrets",
+			namespace,
+			labels.SelectorFromSet(labels.Set{config.AuthSecretLabel: "true"}),
 		),
 		&api_v1.Secret{}, resyncPeriod, secretHandlers)
+	lbc.caSecretLister, lbc.caSecretController = cache.NewInformer(
+		NewListWatchFromClient(
+			lbc.client.Core().RESTClient(),
+			"secrets",
+			namespace,
+			labels.SelectorFromSet(labels.Set{config.CASecretLabel: "true"}),
+		),
+		&api_v1.Secret{}, resyncPeriod, secretHandlers)
+
+	fastCGIParamsHandlers := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			addCfgm := obj.(*api_v1.ConfigMap)
+			lbc.syncFastCGIParams(addCfgm)
+		},
+		DeleteFunc: func(obj interface{}) {
+			remCfgm, isCfgm := obj.(*api_v1.ConfigMap)
+			if !isCfgm {
+				deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					log.
+						WithField("obj", obj).
+						Error("Error received unexpected ConfigMap object, skipping")
+					return
+				}
+				remCfgm, ok = deletedState.Obj.(*api_v1.ConfigMap)
+				if !ok {
+					log.
+						WithField("obj", deletedState.Obj).
+						Error("Error DeletedFinalStateUnknown contained non-ConfigMap object, skipping")
+					return
+				}
+			}
+			lbc.syncFastCGIParams(remCfgm)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			if !reflect.DeepEqual(old, cur) {
+				updateCfgm := cur.(*api_v1.ConfigMap)
+				lbc.syncFastCGIParams(updateCfgm)
+			}
+		},
+	}
+	// only ConfigMaps with fastcgi params are cached, the ingresses referencing them are updated on changes
+	lbc.fastCGIParamsLister, lbc.fastCGIParamsController = cache.NewInformer(
+		NewListWatchFromClient(
+			lbc.client.Core().RESTClient(),
+			"configmaps",
+			namespace,
+			labels.SelectorFromSet(labels.Set{config.FastCGIParamsLabel: "true"}),
+		),
+		&api_v1.ConfigMap{}, resyncPeriod, fastCGIParamsHandlers)
 
 	svcHandlers := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -305,13 +411,82 @@ func NewLoadBalancerController(
 		cache.NewListWatchFromClient(lbc.client.Core().RESTClient(), "endpoints", namespace, fields.Everything()),
 		&api_v1.Endpoints{}, resyncPeriod, endpHandlers)
 
+	if nginxConfig != "" {
+		nginxConfigNS, nginxConfigName, err := parseNginxConfigMaps(nginxConfig)
+		if err != nil {
+			log.WithError(err).Error("Invalid nginx-config setting")
+		} else {
+			if nginxConfigMaps != "" {
+				log.Warning("Both nginx-config and nginx-configmaps are set, nginx-configmaps is only used while the NginxIngressConfig does not exist")
+			}
+			lbc.watchNginxConfig = true
+			lbc.nginxConfigKey = nginxConfig
+			lbc.nicQueue = NewTaskQueue("NginxIngressConfigTaskQueue", lbc.syncNginxConfig, maxRetries, nil)
+
+			nicHandlers := cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) {
+					nic := obj.(*v1alpha1.NginxIngressConfig)
+					if nic.Name == nginxConfigName {
+						log.
+							WithField("namespace", nic.Namespace).
+							WithField("name", nic.Name).
+							Debug("Adding NginxIngressConfig")
+						lbc.nicQueue.Enqueue(obj)
+					}
+				},
+				UpdateFunc: func(old, cur interface{}) {
+					oldNic := old.(*v1alpha1.NginxIngressConfig)
+					curNic := cur.(*v1alpha1.NginxIngressConfig)
+					// status updates written by this controller must not trigger a sync
+					if curNic.Name == nginxConfigName && !reflect.DeepEqual(oldNic.Spec, curNic.Spec) {
+						log.
+							WithField("namespace", curNic.Namespace).
+							WithField("name", curNic.Name).
+							Debug("NginxIngressConfig changed, syncing")
+						lbc.nicQueue.Enqueue(cur)
+					}
+				},
+				DeleteFunc: func(obj interface{}) {
+					nic, isNic := obj.(*v1alpha1.NginxIngressConfig)
+					if !isNic {
+						deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+						if !ok {
+							log.
+								WithField("obj", obj).
+								Error("Error received unexpected NginxIngressConfig object, skipping")
+							return
+						}
+						nic, ok = deletedState.Obj.(*v1alpha1.NginxIngressConfig)
+						if !ok {
+							log.
+								WithField("obj", deletedState.Obj).
+								Error("Error DeletedFinalStateUnknown contained non-NginxIngressConfig object, skipping")
+							return
+						}
+					}
+					if nic.Name == nginxConfigName {
+						log.
+							WithField("namespace", nic.Namespace).
+							WithField("name", nic.Name).
+							Debug("Removing NginxIngressConfig")
+						lbc.nicQueue.Enqueue(obj)
+					}
+				},
+			}
+			lbc.nicLister, lbc.nicController = cache.NewInformer(
+				NewListWatchFromClient(lbc.nicClient, v1alpha1.NginxIngressConfigResource, nginxConfigNS, labels.Everything()),
+				&v1alpha1.NginxIngressConfig{}, resyncPeriod, nicHandlers)
+		}
+	}
+
 	if nginxConfigMaps != "" {
 		nginxConfigMapsNS, nginxConfigMapsName, err := parseNginxConfigMaps(nginxConfigMaps)
 		if err != nil {
 			log.WithError(err).Error("Invalid config-maps setting")
 		} else {
 			lbc.watchNginxConfigMaps = true
-			lbc.cfgmQueue = NewTaskQueue(lbc.syncCfgm, log.WithField("module", "ConfigMapTaskQueue"))
+			lbc.nginxConfigMapsKey = nginxConfigMaps
+			lbc.cfgmQueue = NewTaskQueue("ConfigMapTaskQueue", lbc.syncCfgm, maxRetries, nil)
 
 			cfgmHandlers := cache.ResourceEventHandlerFuncs{
 				AddFunc: func(obj interface{}) {
@@ -369,6 +544,13 @@ func NewLoadBalancerController(
 		}
 	}
 
+	if mainTemplateFile != "" || ingressTemplateFile != "" {
+		lbc.templateWatcher = newTemplateWatcher(mainTemplateFile, ingressTemplateFile, lbc.templatesChanged)
+		if err := lbc.templateWatcher.Load(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &lbc, nil
 }
 
@@ -380,14 +562,42 @@ func (lbc *LoadBalancerController) Stop() {
 
 // Run starts the loadbalancer controller
 func (lbc *LoadBalancerController) Run() {
-	go lbc.ingController.Run(lbc.stopCh)
-	go lbc.svcController.Run(lbc.stopCh)
-	go lbc.endpController.Run(lbc.stopCh)
-	go lbc.secretController.Run(lbc.stopCh)
-	go lbc.ingQueue.Run(time.Second, lbc.stopCh)
+	controllers := []cache.Controller{
+		lbc.ingController,
+		lbc.svcController,
+		lbc.endpController,
+		lbc.tlsSecretController,
+		lbc.authSecretController,
+		lbc.caSecretController,
+		lbc.fastCGIParamsController,
+	}
+	if lbc.watchNginxConfigMaps {
+		controllers = append(controllers, lbc.cfgmController)
+	}
+	if lbc.watchNginxConfig {
+		controllers = append(controllers, lbc.nicController)
+	}
+
+	// the queues are only started when all caches are filled,
+	// otherwise Ingresses would be rendered with missing secrets, endpoints or configs
+	synced := make([]cache.InformerSynced, 0, len(controllers))
+	for _, controller := range controllers {
+		go controller.Run(lbc.stopCh)
+		synced = append(synced, controller.HasSynced)
+	}
+	if !cache.WaitForCacheSync(lbc.stopCh, synced...) {
+		return
+	}
+
+	go lbc.ingQueue.Run(lbc.ingressWorkers, lbc.stopCh)
 	if lbc.watchNginxConfigMaps {
-		go lbc.cfgmController.Run(lbc.stopCh)
-		go lbc.cfgmQueue.Run(time.Second, lbc.stopCh)
+		go lbc.cfgmQueue.Run(1, lbc.stopCh)
+	}
+	if lbc.watchNginxConfig {
+		go lbc.nicQueue.Run(1, lbc.stopCh)
+	}
+	if lbc.templateWatcher != nil {
+		go lbc.templateWatcher.Run(templatePollInterval, lbc.stopCh)
 	}
 	<-lbc.stopCh
 }
@@ -403,7 +613,18 @@ func (lbc *LoadBalancerController) syncSecret(secret *api_v1.Secret) {
 	}
 }
 
-func (lbc *LoadBalancerController) syncCfgm(key string) {
+func (lbc *LoadBalancerController) syncFastCGIParams(cfgm *api_v1.ConfigMap) {
+	key, err := keyFunc(cfgm)
+	if err != nil {
+		log.WithError(err).Error("Error getting key for ConfigMap")
+		return
+	}
+	for _, watcher := range lbc.configMapWatchlist.Watchers(key) {
+		lbc.ingQueue.EnqueueKey(watcher)
+	}
+}
+
+func (lbc *LoadBalancerController) syncCfgm(key string) error {
 	nn := strings.SplitN(key, "/", 2)
 	log.
 		WithField("namespace", nn[0]).
@@ -412,21 +633,124 @@ func (lbc *LoadBalancerController) syncCfgm(key string) {
 
 	obj, cfgmExists, err := lbc.cfgmLister.GetByKey(key)
 	if err != nil {
-		lbc.cfgmQueue.Requeue(key, err)
-		return
+		return err
 	}
 
 	if !cfgmExists {
-		return
+		return nil
+	}
+
+	if lbc.watchNginxConfig {
+		_, nicExists, err := lbc.nicLister.GetByKey(lbc.nginxConfigKey)
+		if err != nil {
+			return err
+		}
+		if nicExists {
+			log.Debug("NginxIngressConfig exists, ignoring ConfigMap")
+			return nil
+		}
 	}
 
 	// var cfg *config.Config
 	cfgm := obj.(*api_v1.ConfigMap)
 	if err := lbc.configurator.ConfigUpdated(cfgm); err != nil {
-		lbc.cfgmQueue.Requeue(key, err)
+		return err
+	}
+
+	lbc.enqueueAllIngresses()
+	return nil
+}
+
+func (lbc *LoadBalancerController) syncNginxConfig(key string) error {
+	nn := strings.SplitN(key, "/", 2)
+	log.
+		WithField("namespace", nn[0]).
+		WithField("name", nn[1]).
+		Debug("Syncing NginxIngressConfig")
+
+	obj, nicExists, err := lbc.nicLister.GetByKey(key)
+	if err != nil {
+		return err
+	}
+
+	if !nicExists {
+		return lbc.nginxConfigDeleted(key)
+	}
+
+	nic := obj.(*v1alpha1.NginxIngressConfig)
+	warning, err := lbc.configurator.NginxConfigUpdated(nic)
+	lbc.updateNginxConfigStatus(nic, warning, err)
+	if err != nil {
+		return err
+	}
+
+	lbc.enqueueAllIngresses()
+	return nil
+}
+
+// nginxConfigDeleted falls back to the -nginx-configmaps ConfigMap, or the default config
+// if it does not exist, and renders all Ingresses again
+func (lbc *LoadBalancerController) nginxConfigDeleted(key string) error {
+	var cfgm *api_v1.ConfigMap
+	if lbc.watchNginxConfigMaps {
+		obj, cfgmExists, err := lbc.cfgmLister.GetByKey(lbc.nginxConfigMapsKey)
+		if err != nil {
+			return err
+		}
+		if cfgmExists {
+			cfgm = obj.(*api_v1.ConfigMap)
+		}
+	}
+
+	if err := lbc.configurator.NginxConfigDeleted(key, cfgm); err != nil {
+		return err
+	}
+
+	lbc.enqueueAllIngresses()
+	return nil
+}
+
+// updateNginxConfigStatus reports the result of a sync in the status of the NginxIngressConfig
+func (lbc *LoadBalancerController) updateNginxConfigStatus(nic *v1alpha1.NginxIngressConfig, warning, err error) {
+	status := v1alpha1.NginxIngressConfigStatus{
+		ObservedGeneration: nic.Generation,
+		RenderedGeneration: nic.Status.RenderedGeneration,
+	}
+	if err == nil {
+		status.RenderedGeneration = nic.Generation
+	} else if err != warning {
+		status.Errors = append(status.Errors, err.Error())
+	}
+	if warning != nil {
+		status.Errors = append(status.Errors, validationErrorMessages(warning)...)
+	}
+
+	if reflect.DeepEqual(nic.Status, status) {
 		return
 	}
 
+	// the cached object must not be modified
+	updated := *nic
+	updated.Status = status
+	if _, uerr := v1alpha1.UpdateStatus(lbc.nicClient, &updated); uerr != nil {
+		log.
+			WithField("namespace", nic.Namespace).
+			WithField("name", nic.Name).
+			WithError(uerr).
+			Error("Error updating NginxIngressConfig status")
+	}
+}
+
+// templatesChanged updates the templates and renders all Ingresses again
+func (lbc *LoadBalancerController) templatesChanged(mainTemplate, ingressTemplate string) error {
+	if err := lbc.configurator.TemplatesUpdated(mainTemplate, ingressTemplate); err != nil {
+		return err
+	}
+	lbc.enqueueAllIngresses()
+	return nil
+}
+
+func (lbc *LoadBalancerController) enqueueAllIngresses() {
 	ings, _ := lbc.ingLister.List()
 	for _, ing := range ings.Items {
 		if !isNginxIngress(&ing) {
@@ -436,11 +760,10 @@ func (lbc *LoadBalancerController) syncCfgm(key string) {
 	}
 }
 
-func (lbc *LoadBalancerController) syncIng(key string) {
-	_, ingExists, err := lbc.ingLister.Store.GetByKey(key)
+func (lbc *LoadBalancerController) syncIng(key string) error {
+	_, ingExists, err := lbc.ingLister.GetByKey(key)
 	if err != nil {
-		lbc.ingQueue.Requeue(key, err)
-		return
+		return err
 	}
 
 	if !ingExists {
@@ -449,20 +772,25 @@ func (lbc *LoadBalancerController) syncIng(key string) {
 			WithField("namespace", nn[0]).
 			WithField("name", nn[1]).
 			Info("Ingress no longer exists, deleting")
-		if err := lbc.configurator.IngressDeleted(key); err != nil {
-			lbc.ingQueue.RequeueAfter(key, err, 5*time.Second)
-		}
-		return
+		return lbc.configurator.IngressDeleted(key)
 	}
 
-	if err := lbc.configurator.IngressUpdated(key); err != nil {
-		lbc.ingQueue.RequeueAfter(key, err, 5*time.Second)
+	return lbc.configurator.IngressUpdated(key)
+}
+
+// ingressSyncFailed records a warning event when the ingress queue gives up on an ingress,
+// it is synced again on the next change of the ingress or its dependencies
+func (lbc *LoadBalancerController) ingressSyncFailed(key string, err error) {
+	obj, ingExists, gerr := lbc.ingLister.GetByKey(key)
+	if gerr != nil || !ingExists {
 		return
 	}
+	lbc.recorder.Eventf(obj.(*extensions.Ingress), api_v1.EventTypeWarning, ReasonSyncFailed,
+		"Giving up after repeated errors, waiting for the next change: %v", err)
 }
 
 func (lbc *LoadBalancerController) getIngressByKey(ingKey string) (*extensions.Ingress, error) {
-	obj, ingExists, err := lbc.ingLister.Store.GetByKey(ingKey)
+	obj, ingExists, err := lbc.ingLister.GetByKey(ingKey)
 	if err != nil {
 		return nil, err
 	}
@@ -474,16 +802,37 @@ func (lbc *LoadBalancerController) getIngressByKey(ingKey string) (*extensions.I
 	return ing, nil
 }
 
-func (lbc *LoadBalancerController) getSecret(namespace, name string) (*api_v1.Secret, error) {
-	secret, err := lbc.client.Core().Secrets(namespace).Get(name, meta_v1.GetOptions{})
+// getFastCGIParamsConfigMap returns the fastcgi params ConfigMap from the informer cache,
+// a NotFound error is returned if the ConfigMap is not cached
+func (lbc *LoadBalancerController) getFastCGIParamsConfigMap(namespace, name string) (*api_v1.ConfigMap, error) {
+	obj, exists, err := lbc.fastCGIParamsLister.GetByKey(namespace + "/" + name)
 	if err != nil {
 		return nil, err
 	}
-	return secret, nil
+	if !exists {
+		return nil, api_errors.NewNotFound(api_v1.Resource("configmaps"), name)
+	}
+	return obj.(*api_v1.ConfigMap), nil
+}
+
+// getSecret returns the secret from the informer cache,
+// a NotFound error is returned if the secret is not cached
+func (lbc *LoadBalancerController) getSecret(namespace, name string) (*api_v1.Secret, error) {
+	key := namespace + "/" + name
+	for _, store := range []cache.Store{lbc.tlsSecretLister, lbc.authSecretLister, lbc.caSecretLister} {
+		obj, exists, err := store.GetByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return obj.(*api_v1.Secret), nil
+		}
+	}
+	return nil, api_errors.NewNotFound(api_v1.Resource("secrets"), name)
 }
 
 func (lbc *LoadBalancerController) getIngressEx(ingKey string) (*config.IngressEx, error) {
-	obj, ingExists, err := lbc.ingLister.Store.GetByKey(ingKey)
+	obj, ingExists, err := lbc.ingLister.GetByKey(ingKey)
 	if err != nil {
 		return nil, err
 	}
@@ -539,21 +888,18 @@ func (lbc *LoadBalancerController) getIngressesForService(svc *api_v1.Service) [
 	return ings
 }
 
+// getIngressForEndpoints returns the Ingress' using the service of the endpoints,
+// endpoints and services share the same key
 func (lbc *LoadBalancerController) getIngressForEndpoints(obj interface{}) []extensions.Ingress {
-	var ings []extensions.Ingress
 	endp := obj.(*api_v1.Endpoints)
-	svcKey := endp.GetNamespace() + "/" + endp.GetName()
-	svcObj, svcExists, err := lbc.svcLister.GetByKey(svcKey)
+	ings, err := lbc.ingLister.GetServiceKeyIngress(endp.Namespace + "/" + endp.Name)
 	if err != nil {
 		log.
 			WithField("namespace", endp.Namespace).
 			WithField("name", endp.Name).
 			WithError(err).
-			Error("Error getting service from the cache")
-	} else {
-		if svcExists {
-			ings = append(ings, lbc.getIngressesForService(svcObj.(*api_v1.Service))...)
-		}
+			Debug("Ignoring Endpoints")
+		return nil
 	}
 	return ings
 }
@@ -566,7 +912,7 @@ func (lbc *LoadBalancerController) createIngressEx(ing *extensions.Ingress) (*co
 	secrets := map[string]*api_v1.Secret{}
 	for _, tls := range ing.Spec.TLS {
 		secretName := tls.SecretName
-		secret, err := lbc.client.Core().Secrets(ing.Namespace).Get(secretName, meta_v1.GetOptions{})
+		secret, err := lbc.getSecret(ing.Namespace, secretName)
 		if err != nil {
 			return nil, err
 		}
@@ -631,68 +977,39 @@ func (lbc *LoadBalancerController) getEndpointsForIngressBackend(backend *extens
 	return result, nil
 }
 
+// getEndpointsForPort returns the endpoints of all subsets for the given service port.
+// Like kube-proxy, the endpoint ports are matched by the name of the service port,
+// so named target ports are resolved by the endpoints controller.
 func (lbc *LoadBalancerController) getEndpointsForPort(endps api_v1.Endpoints, ingSvcPort intstr.IntOrString, svc *api_v1.Service) ([]string, error) {
-	var targetPort int32
-	var err error
-	found := false
-
-	for _, port := range svc.Spec.Ports {
+	var svcPort *api_v1.ServicePort
+	for i, port := range svc.Spec.Ports {
 		if (ingSvcPort.Type == intstr.Int && port.Port == int32(ingSvcPort.IntValue())) || (ingSvcPort.Type == intstr.String && port.Name == ingSvcPort.String()) {
-			targetPort, err = lbc.getTargetPort(&port, svc)
-			if err != nil {
-				return nil, fmt.Errorf("Error determining target port for port %v in Ingress: %v", ingSvcPort, err)
-			}
-			found = true
+			svcPort = &svc.Spec.Ports[i]
 			break
 		}
 	}
 
-	if !found {
+	if svcPort == nil {
 		return nil, fmt.Errorf("No port %v in service %s", ingSvcPort, svc.Name)
 	}
 
+	var endpoints []string
 	for _, subset := range endps.Subsets {
 		for _, port := range subset.Ports {
-			if port.Port == targetPort {
-				var endpoints []string
-				for _, address := range subset.Addresses {
-					endpoint := fmt.Sprintf("%v:%v", address.IP, port.Port)
-					endpoints = append(endpoints, endpoint)
-				}
-				return endpoints, nil
+			if port.Name != svcPort.Name {
+				continue
+			}
+			for _, address := range subset.Addresses {
+				endpoint := fmt.Sprintf("%v:%v", address.IP, port.Port)
+				endpoints = append(endpoints, endpoint)
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("No endpoints for target port %v in service %s", targetPort, svc.Name)
-}
-
-func (lbc *LoadBalancerController) getTargetPort(svcPort *api_v1.ServicePort, svc *api_v1.Service) (int32, error) {
-	if (svcPort.TargetPort == intstr.IntOrString{}) {
-		return svcPort.Port, nil
-	}
-
-	if svcPort.TargetPort.Type == intstr.Int {
-		return int32(svcPort.TargetPort.IntValue()), nil
-	}
-
-	pods, err := lbc.client.Core().Pods(svc.Namespace).List(meta_v1.ListOptions{LabelSelector: labels.Set(svc.Spec.Selector).String()})
-	if err != nil {
-		return 0, fmt.Errorf("Error getting pod information: %v", err)
-	}
-
-	if len(pods.Items) == 0 {
-		return 0, fmt.Errorf("No pods of service %s", svc.Name)
-	}
-
-	pod := &pods.Items[0]
-
-	portNum, err := FindPort(pod, svcPort)
-	if err != nil {
-		return 0, fmt.Errorf("Error finding named port %v in pod %s: %v", svcPort, pod.Name, err)
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("No endpoints for port %v in service %s", ingSvcPort, svc.Name)
 	}
-
-	return portNum, nil
+	return endpoints, nil
 }
 
 func (lbc *LoadBalancerController) getServiceForIngressBackend(backend *extensions.IngressBackend, namespace string) (*api_v1.Service, error) {
@@ -724,3 +1041,19 @@ func isNginxIngress(ing *extensions.Ingress) bool {
 
 	return true
 }
+
+// validationErrorMessages returns the messages of all errors wrapped in the given error
+func validationErrorMessages(err error) []string {
+	if cerr, ok := err.(errors.ErrObjectContext); ok {
+		err = cerr.WrappedError()
+	}
+	verr, ok := err.(config.ValidationError)
+	if !ok {
+		return []string{err.Error()}
+	}
+	messages := []string{}
+	for _, e := range verr {
+		messages = append(messages, e.Error())
+	}
+	return messages
+}
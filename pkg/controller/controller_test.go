@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/tools/cache"
+)
+
+type ConfiguratorMock struct {
+	Configurator
+	mock.Mock
+}
+
+func (m *ConfiguratorMock) NginxConfigDeleted(nicKey string, cfgm *api_v1.ConfigMap) error {
+	args := m.Called(nicKey, cfgm)
+	return args.Error(0)
+}
+
+// TaskQueueMock records the keys of the enqueued objects
+type TaskQueueMock struct {
+	TaskQueue
+	keys []string
+}
+
+func (q *TaskQueueMock) Enqueue(obj interface{}) {
+	key, _ := keyFunc(obj)
+	q.keys = append(q.keys, key)
+}
+
+// controllerMock is a cache.Controller that has synced when synced is set to 1
+type controllerMock struct {
+	cache.Controller
+	synced int32
+}
+
+func (c *controllerMock) Run(stopCh <-chan struct{}) {
+	<-stopCh
+}
+
+func (c *controllerMock) HasSynced() bool {
+	return atomic.LoadInt32(&c.synced) == 1
+}
+
+// runQueueMock is a TaskQueue that closes started when it is run
+type runQueueMock struct {
+	TaskQueue
+	started chan struct{}
+}
+
+func (q *runQueueMock) Run(workers int, stopCh <-chan struct{}) {
+	close(q.started)
+}
+
+func TestRunWaitsForCacheSync(t *testing.T) {
+	assert := assert.New(t)
+	controllers := []*controllerMock{{}, {}, {}, {}, {}, {}, {}}
+	ingQueue := &runQueueMock{started: make(chan struct{})}
+	lbc := &LoadBalancerController{
+		ingController:           controllers[0],
+		svcController:           controllers[1],
+		endpController:          controllers[2],
+		tlsSecretController:     controllers[3],
+		authSecretController:    controllers[4],
+		caSecretController:      controllers[5],
+		fastCGIParamsController: controllers[6],
+		ingQueue:                ingQueue,
+		stopCh:                  make(chan struct{}),
+	}
+	go lbc.Run()
+	defer close(lbc.stopCh)
+
+	for _, controller := range controllers[:6] {
+		atomic.StoreInt32(&controller.synced, 1)
+	}
+	select {
+	case <-ingQueue.started:
+		assert.Fail("ingress queue started before all caches have synced")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	atomic.StoreInt32(&controllers[6].synced, 1)
+	select {
+	case <-ingQueue.started:
+	case <-time.After(time.Second):
+		assert.Fail("ingress queue not started after all caches have synced")
+	}
+}
+
+func TestNginxConfigDeleted(t *testing.T) {
+	cfgm := &api_v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-config", Namespace: "default"},
+	}
+	ing := &extensions.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: "default"},
+	}
+	otherIng := &extensions.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "other",
+			Namespace:   "default",
+			Annotations: map[string]string{ingressClassKey: "other"},
+		},
+	}
+
+	var (
+		lbc          *LoadBalancerController
+		configurator *ConfiguratorMock
+		ingQueue     *TaskQueueMock
+	)
+	beforeEach := func() {
+		configurator = &ConfiguratorMock{}
+		ingQueue = &TaskQueueMock{}
+		lbc = &LoadBalancerController{
+			configurator:         configurator,
+			ingQueue:             ingQueue,
+			ingLister:            StoreToIngressLister{cache.NewIndexer(keyFunc, cache.Indexers{})},
+			cfgmLister:           StoreToConfigMapLister{cache.NewStore(keyFunc)},
+			watchNginxConfigMaps: true,
+			nginxConfigMapsKey:   "default/nginx-config",
+		}
+		lbc.ingLister.Add(ing)
+		lbc.ingLister.Add(otherIng)
+	}
+
+	t.Run("falls back to the ConfigMap and enqueues all Ingresses", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+		lbc.cfgmLister.Add(cfgm)
+		configurator.On("NginxConfigDeleted", "default/nic", cfgm).Return(nil)
+
+		assert.NoError(lbc.nginxConfigDeleted("default/nic"))
+		configurator.AssertExpectations(t)
+		assert.Equal([]string{"default/ing"}, ingQueue.keys)
+	})
+
+	t.Run("falls back to the default config without the ConfigMap", func(t *testing.T) {
+		beforeEach()
+		assert := assert.New(t)
+		configurator.On("NginxConfigDeleted", "default/nic", (*api_v1.ConfigMap)(nil)).Return(nil)
+
+		assert.NoError(lbc.nginxConfigDeleted("default/nic"))
+		configurator.AssertExpectations(t)
+		assert.Equal([]string{"default/ing"}, ingQueue.keys)
+	})
+}
+
+func TestGetSecret(t *testing.T) {
+	tlsSecret := &api_v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"},
+		Type:       api_v1.SecretTypeTLS,
+	}
+	authSecret := &api_v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+	}
+	caSecret := &api_v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "default"},
+	}
+
+	lbc := &LoadBalancerController{
+		tlsSecretLister:  cache.NewStore(keyFunc),
+		authSecretLister: cache.NewStore(keyFunc),
+		caSecretLister:   cache.NewStore(keyFunc),
+	}
+	lbc.tlsSecretLister.Add(tlsSecret)
+	lbc.authSecretLister.Add(authSecret)
+	lbc.caSecretLister.Add(caSecret)
+
+	t.Run("returns secrets from the caches", func(t *testing.T) {
+		assert := assert.New(t)
+
+		secret, err := lbc.getSecret("default", "tls")
+		assert.NoError(err)
+		assert.Equal(tlsSecret, secret)
+
+		secret, err = lbc.getSecret("default", "auth")
+		assert.NoError(err)
+		assert.Equal(authSecret, secret)
+
+		secret, err = lbc.getSecret("default", "ca")
+		assert.NoError(err)
+		assert.Equal(caSecret, secret)
+	})
+
+	t.Run("returns a NotFound error for missing secrets", func(t *testing.T) {
+		assert := assert.New(t)
+
+		secret, err := lbc.getSecret("other", "tls")
+		assert.Nil(secret)
+		assert.True(api_errors.IsNotFound(err))
+	})
+}
+
+func TestGetFastCGIParamsConfigMap(t *testing.T) {
+	assert := assert.New(t)
+	cfgm := &api_v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "php-params", Namespace: "default"},
+	}
+	lbc := &LoadBalancerController{
+		fastCGIParamsLister: cache.NewStore(keyFunc),
+	}
+	lbc.fastCGIParamsLister.Add(cfgm)
+
+	cached, err := lbc.getFastCGIParamsConfigMap("default", "php-params")
+	assert.NoError(err)
+	assert.Equal(cfgm, cached)
+
+	cached, err = lbc.getFastCGIParamsConfigMap("other", "php-params")
+	assert.Nil(cached)
+	assert.True(api_errors.IsNotFound(err))
+}
+
+func TestGetEndpointsForPort(t *testing.T) {
+	lbc := &LoadBalancerController{}
+	svc := &api_v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+		Spec: api_v1.ServiceSpec{
+			Ports: []api_v1.ServicePort{
+				api_v1.ServicePort{Name: "http", Port: 80, TargetPort: intstr.FromString("web")},
+				api_v1.ServicePort{Name: "metrics", Port: 9000, TargetPort: intstr.FromInt(9000)},
+			},
+		},
+	}
+	endps := api_v1.Endpoints{
+		Subsets: []api_v1.EndpointSubset{
+			api_v1.EndpointSubset{
+				Addresses: []api_v1.EndpointAddress{api_v1.EndpointAddress{IP: "10.0.0.1"}},
+				Ports: []api_v1.EndpointPort{
+					api_v1.EndpointPort{Name: "http", Port: 8080},
+					api_v1.EndpointPort{Name: "metrics", Port: 9000},
+				},
+			},
+			api_v1.EndpointSubset{
+				Addresses: []api_v1.EndpointAddress{
+					api_v1.EndpointAddress{IP: "10.0.0.2"},
+					api_v1.EndpointAddress{IP: "10.0.0.3"},
+				},
+				Ports: []api_v1.EndpointPort{
+					api_v1.EndpointPort{Name: "http", Port: 8081},
+				},
+			},
+		},
+	}
+
+	t.Run("resolves named target ports of all subsets", func(t *testing.T) {
+		assert := assert.New(t)
+		endpoints, err := lbc.getEndpointsForPort(endps, intstr.FromInt(80), svc)
+		assert.NoError(err)
+		assert.Equal([]string{"10.0.0.1:8080", "10.0.0.2:8081", "10.0.0.3:8081"}, endpoints)
+	})
+
+	t.Run("matches service ports by name", func(t *testing.T) {
+		assert := assert.New(t)
+		endpoints, err := lbc.getEndpointsForPort(endps, intstr.FromString("metrics"), svc)
+		assert.NoError(err)
+		assert.Equal([]string{"10.0.0.1:9000"}, endpoints)
+	})
+
+	t.Run("returns an error for unknown ports", func(t *testing.T) {
+		_, err := lbc.getEndpointsForPort(endps, intstr.FromInt(443), svc)
+		assert.Error(t, err)
+	})
+
+	t.Run("returns an error if there are no endpoints", func(t *testing.T) {
+		_, err := lbc.getEndpointsForPort(api_v1.Endpoints{}, intstr.FromInt(80), svc)
+		assert.Error(t, err)
+	})
+}
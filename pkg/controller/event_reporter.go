@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetechnick/nginx-ingress/pkg/apis/nginxingress/v1alpha1"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
+	"github.com/thetechnick/nginx-ingress/pkg/renderer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/tools/record"
+)
+
+// Event reasons recorded by the EventReporter
+const (
+	ReasonInvalidAnnotation   = "InvalidAnnotation"
+	ReasonInvalidConfigMapKey = "InvalidConfigMapKey"
+	ReasonInvalidConfigField  = "InvalidConfigField"
+	ReasonInvalidConfig       = "InvalidConfig"
+	ReasonInvalidSecret       = "InvalidSecret"
+	ReasonInvalidTemplate     = "InvalidTemplate"
+	ReasonSecretNotFound      = "SecretNotFound"
+	ReasonSecretAccessDenied  = "SecretAccessDenied"
+	ReasonSecretError         = "SecretError"
+	ReasonConfigMapNotFound   = "ConfigMapNotFound"
+	ReasonConfigMapError      = "ConfigMapError"
+	ReasonConfigRejected      = "ConfigRejected"
+	ReasonIngressConflict     = "IngressConflict"
+	ReasonIngressRejected     = "IngressRejected"
+	ReasonDependencyError     = "DependencyError"
+	ReasonSyncFailed          = "SyncFailed"
+	ReasonWarningsResolved    = "WarningsResolved"
+)
+
+// EventReporter records config errors as events on the objects they belong to
+type EventReporter interface {
+	// Report records a warning event for every error wrapped in the errors.ErrObjectContext,
+	// identical events are only recorded once per object generation.
+	// Errors without a more specific type are recorded with the given reason.
+	Report(reason string, err error)
+	// Clear forgets the warnings reported for the object and records
+	// a normal event if there were any, so they are reported again when they reappear
+	Clear(obj runtime.Object)
+	// Forget drops the warnings reported for a deleted object without recording an event
+	Forget(obj runtime.Object)
+}
+
+// NewEventReporter creates a new EventReporter recording to the given recorder
+func NewEventReporter(recorder record.EventRecorder) EventReporter {
+	return &eventReporter{
+		recorder: recorder,
+		reported: map[string]*reportedEvents{},
+		log:      log.WithField("module", "EventReporter"),
+	}
+}
+
+type eventReporter struct {
+	recorder record.EventRecorder
+	// reported events by object
+	reported map[string]*reportedEvents
+	mutex    sync.Mutex
+	log      *log.Entry
+}
+
+// reportedEvents are the events reported for a generation of an object
+type reportedEvents struct {
+	generation string
+	events     map[string]bool
+}
+
+func (r *eventReporter) Report(reason string, err error) {
+	if err == nil {
+		return
+	}
+
+	cerr, ok := err.(errors.ErrObjectContext)
+	if !ok {
+		r.log.
+			WithError(err).
+			Error("Could not record error event due to missing context")
+		return
+	}
+
+	errs := []error{cerr.WrappedError()}
+	if verr, ok := cerr.WrappedError().(config.ValidationError); ok {
+		errs = verr
+	}
+	for _, e := range errs {
+		r.event(cerr.Object(), eventReason(reason, e), e.Error())
+	}
+}
+
+func (r *eventReporter) Clear(obj runtime.Object) {
+	key, _, err := objectKey(obj)
+	if err != nil {
+		return
+	}
+
+	r.mutex.Lock()
+	_, hadWarnings := r.reported[key]
+	delete(r.reported, key)
+	r.mutex.Unlock()
+
+	if hadWarnings {
+		r.recorder.Event(obj, api_v1.EventTypeNormal, ReasonWarningsResolved, "All warnings have been resolved")
+	}
+}
+
+func (r *eventReporter) Forget(obj runtime.Object) {
+	key, _, err := objectKey(obj)
+	if err != nil {
+		return
+	}
+
+	r.mutex.Lock()
+	delete(r.reported, key)
+	r.mutex.Unlock()
+}
+
+// event records a warning event, unless it was already recorded for the current object generation
+func (r *eventReporter) event(obj runtime.Object, reason, message string) {
+	key, generation, err := objectKey(obj)
+	if err != nil {
+		r.log.WithError(err).Error("Could not record event")
+		return
+	}
+
+	r.mutex.Lock()
+	reported, ok := r.reported[key]
+	if !ok || reported.generation != generation {
+		reported = &reportedEvents{
+			generation: generation,
+			events:     map[string]bool{},
+		}
+		r.reported[key] = reported
+	}
+	event := reason + "\n" + message
+	duplicate := reported.events[event]
+	reported.events[event] = true
+	r.mutex.Unlock()
+
+	if !duplicate {
+		r.recorder.Event(obj, api_v1.EventTypeWarning, reason, message)
+	}
+}
+
+// eventReason returns the reason for the type of the error or the default reason
+func eventReason(defaultReason string, err error) string {
+	switch err.(type) {
+	case *config.IngressAnnotationError:
+		return ReasonInvalidAnnotation
+	case *config.ConfigMapKeyError:
+		return ReasonInvalidConfigMapKey
+	case *config.ConfigFieldError:
+		return ReasonInvalidConfigField
+	case *config.SecretAccessDeniedError:
+		return ReasonSecretAccessDenied
+	case *renderer.TemplateError:
+		return ReasonInvalidTemplate
+	}
+	return defaultReason
+}
+
+// objectKey identifies the object and its generation,
+// objects without generation, like ConfigMaps, use the resource version instead
+func objectKey(obj runtime.Object) (key string, generation string, err error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	key = fmt.Sprintf("%T/%s/%s", obj, m.GetNamespace(), m.GetName())
+	generation = m.GetResourceVersion()
+	if g := objectGeneration(obj); g != 0 {
+		generation = strconv.FormatInt(g, 10)
+	}
+	return
+}
+
+// objectGeneration returns the generation of the objects that have one
+func objectGeneration(obj runtime.Object) int64 {
+	switch o := obj.(type) {
+	case *v1beta1.Ingress:
+		return o.Generation
+	case *v1alpha1.NginxIngressConfig:
+		return o.Generation
+	}
+	return 0
+}
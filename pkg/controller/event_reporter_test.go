@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func TestEventReporter(t *testing.T) {
+	ing := &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "ing1",
+			Namespace:  "default",
+			Generation: 1,
+		},
+	}
+	annotationErr := &config.IngressAnnotationError{Annotation: "nginx.org/hsts", ValidationError: fmt.Errorf("invalid")}
+	secretErr := fmt.Errorf("secret not found")
+	warnings := errors.WrapInObjectContext(config.ValidationError{annotationErr, secretErr}, ing)
+
+	t.Run("records one event per validation error", func(t *testing.T) {
+		recorder := &RecorderMock{}
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		r := NewEventReporter(recorder)
+
+		r.Report(ReasonInvalidConfig, warnings)
+		recorder.AssertNumberOfCalls(t, "Event", 2)
+		recorder.AssertCalled(t, "Event", ing, api_v1.EventTypeWarning, ReasonInvalidAnnotation, annotationErr.Error())
+		recorder.AssertCalled(t, "Event", ing, api_v1.EventTypeWarning, ReasonInvalidConfig, secretErr.Error())
+	})
+
+	t.Run("deduplicates events per generation", func(t *testing.T) {
+		recorder := &RecorderMock{}
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		r := NewEventReporter(recorder)
+
+		r.Report(ReasonSecretNotFound, errors.WrapInObjectContext(secretErr, ing))
+		r.Report(ReasonSecretNotFound, errors.WrapInObjectContext(secretErr, ing))
+		recorder.AssertNumberOfCalls(t, "Event", 1)
+
+		updated := *ing
+		updated.Generation = 2
+		r.Report(ReasonSecretNotFound, errors.WrapInObjectContext(secretErr, &updated))
+		recorder.AssertNumberOfCalls(t, "Event", 2)
+	})
+
+	t.Run("clears reported warnings", func(t *testing.T) {
+		recorder := &RecorderMock{}
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		r := NewEventReporter(recorder)
+
+		r.Clear(ing)
+		recorder.AssertNotCalled(t, "Event", ing, api_v1.EventTypeNormal, ReasonWarningsResolved, mock.Anything)
+
+		r.Report(ReasonSecretNotFound, errors.WrapInObjectContext(secretErr, ing))
+		r.Clear(ing)
+		recorder.AssertCalled(t, "Event", ing, api_v1.EventTypeNormal, ReasonWarningsResolved, mock.Anything)
+
+		// reported again after clearing
+		r.Report(ReasonSecretNotFound, errors.WrapInObjectContext(secretErr, ing))
+		recorder.AssertNumberOfCalls(t, "Event", 3)
+	})
+
+	t.Run("forgets deleted objects", func(t *testing.T) {
+		recorder := &RecorderMock{}
+		recorder.On("Event", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		r := NewEventReporter(recorder)
+
+		r.Report(ReasonSecretNotFound, errors.WrapInObjectContext(secretErr, ing))
+		r.Forget(&v1beta1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ing1", Namespace: "default"}})
+		assert.Empty(t, r.(*eventReporter).reported)
+		recorder.AssertNumberOfCalls(t, "Event", 1)
+	})
+}
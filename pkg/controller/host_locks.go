@@ -0,0 +1,54 @@
+package controller
+
+import "sync"
+
+// hostLocks serializes updates of ingresses that share hosts.
+// A set of hosts is locked as a whole, so callers can never deadlock
+// by locking overlapping sets in different orders.
+// The zero value is ready to use.
+type hostLocks struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	locked map[string]bool
+}
+
+// Lock blocks until none of the hosts is locked and locks all of them
+func (l *hostLocks) Lock(hosts []string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.cond == nil {
+		l.cond = sync.NewCond(&l.mutex)
+		l.locked = map[string]bool{}
+	}
+	for !l.available(hosts) {
+		l.cond.Wait()
+	}
+	for _, host := range hosts {
+		l.locked[host] = true
+	}
+}
+
+// Unlock releases the hosts locked by a previous call of Lock
+func (l *hostLocks) Unlock(hosts []string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, host := range hosts {
+		delete(l.locked, host)
+	}
+	if l.cond != nil {
+		l.cond.Broadcast()
+	}
+}
+
+// available checks if none of the hosts is locked,
+// the caller must hold the mutex
+func (l *hostLocks) available(hosts []string) bool {
+	for _, host := range hosts {
+		if l.locked[host] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHostLocks(t *testing.T) {
+	l := hostLocks{}
+	l.Lock([]string{"one.example.com", "two.example.com"})
+
+	// disjoint hosts can be locked
+	l.Lock([]string{"three.example.com"})
+	l.Unlock([]string{"three.example.com"})
+
+	locked := make(chan struct{})
+	go func() {
+		l.Lock([]string{"three.example.com", "two.example.com"})
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("overlapping hosts were locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	l.Unlock([]string{"one.example.com", "two.example.com"})
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("hosts were not released")
+	}
+}
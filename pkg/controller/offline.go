@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/thetechnick/nginx-ingress/pkg/collision"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/storage"
+	"github.com/thetechnick/nginx-ingress/pkg/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// OfflineController renders the nginx config for a fixed set of objects without a cluster,
+// using the same caches and configurator as the LoadBalancerController
+type OfflineController struct {
+	lbc             *LoadBalancerController
+	nginxConfigMaps string
+	templates       templates
+}
+
+// NewOfflineController creates a controller for the given ConfigMaps, Ingresses, Services, Endpoints and Secrets.
+// Like in the cluster, only TLS secrets and secrets with the auth or CA secret label are used.
+// nginxConfigMaps selects the ConfigMap with the nginx configuration, the defaults are used if it is empty.
+// The templates replace the embedded templates unless they are empty, like the template files of the LoadBalancerController.
+func NewOfflineController(
+	objects []runtime.Object,
+	nginxConfigMaps string,
+	mainTemplate string,
+	ingressTemplate string,
+	validator validation.Validator,
+	allowSnippetAnnotations bool,
+	collisionHandler collision.Handler,
+	recorder record.EventRecorder,
+	mcs storage.MainConfigStorage,
+	scs storage.ServerConfigStorage,
+) (*OfflineController, error) {
+	lbc := &LoadBalancerController{
+		secretWatchlist:     NewWatchlist(),
+		configMapWatchlist:  NewWatchlist(),
+		recorder:            recorder,
+		svcLister:           cache.NewStore(keyFunc),
+		tlsSecretLister:     cache.NewStore(keyFunc),
+		authSecretLister:    cache.NewStore(keyFunc),
+		caSecretLister:      cache.NewStore(keyFunc),
+		fastCGIParamsLister: cache.NewStore(keyFunc),
+	}
+	lbc.ingLister.Indexer = cache.NewIndexer(keyFunc, cache.Indexers{ingressServiceIndex: ingressServiceIndexFunc})
+	lbc.endpLister.Store = cache.NewStore(keyFunc)
+	lbc.cfgmLister.Store = cache.NewStore(keyFunc)
+
+	for _, obj := range objects {
+		var err error
+		switch o := obj.(type) {
+		case *extensions.Ingress:
+			if isNginxIngress(o) {
+				err = lbc.ingLister.Add(o)
+			}
+		case *api_v1.Service:
+			err = lbc.svcLister.Add(o)
+		case *api_v1.Endpoints:
+			err = lbc.endpLister.Add(o)
+		case *api_v1.ConfigMap:
+			err = lbc.cfgmLister.Add(o)
+			if err == nil && o.Labels[config.FastCGIParamsLabel] == "true" {
+				err = lbc.fastCGIParamsLister.Add(o)
+			}
+		case *api_v1.Secret:
+			if o.Type == api_v1.SecretTypeTLS {
+				err = lbc.tlsSecretLister.Add(o)
+			} else if o.Labels[config.AuthSecretLabel] == "true" {
+				err = lbc.authSecretLister.Add(o)
+			} else if o.Labels[config.CASecretLabel] == "true" {
+				err = lbc.caSecretLister.Add(o)
+			}
+		default:
+			err = fmt.Errorf("unsupported object type %T", obj)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lbc.configurator = NewConfigurator(
+		&ingressAccessorFuncs{lbc.getIngressByKey},
+		&secretAccessorFuncs{lbc.getSecret},
+		&configMapAccessorFuncs{lbc.getFastCGIParamsConfigMap},
+		&endpointsAccessorFuncs{lbc.getEndpointsForIngressBackend},
+
+		lbc.secretWatchlist,
+		lbc.configMapWatchlist,
+
+		recorder,
+		validator,
+		allowSnippetAnnotations,
+		collisionHandler,
+		mcs,
+		scs,
+	)
+
+	return &OfflineController{
+		lbc:             lbc,
+		nginxConfigMaps: nginxConfigMaps,
+		templates:       templates{main: mainTemplate, ingress: ingressTemplate},
+	}, nil
+}
+
+// Render renders the main config and the server configs of all Ingresses into the storages,
+// Ingresses are processed in the order of their keys
+func (c *OfflineController) Render() error {
+	if err := c.lbc.configurator.TemplatesUpdated(c.templates.main, c.templates.ingress); err != nil {
+		return err
+	}
+
+	cfgm := &api_v1.ConfigMap{}
+	if c.nginxConfigMaps != "" {
+		if _, _, err := parseNginxConfigMaps(c.nginxConfigMaps); err != nil {
+			return err
+		}
+		obj, exists, err := c.lbc.cfgmLister.GetByKey(c.nginxConfigMaps)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("ConfigMap %s not found", c.nginxConfigMaps)
+		}
+		cfgm = obj.(*api_v1.ConfigMap)
+	}
+	if err := c.lbc.configurator.ConfigUpdated(cfgm); err != nil {
+		return err
+	}
+
+	keys := c.lbc.ingLister.ListKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := c.lbc.configurator.IngressUpdated(key); err != nil {
+			return fmt.Errorf("error rendering Ingress %s: %v", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetechnick/nginx-ingress/pkg/collision"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/memory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/tools/record"
+)
+
+func offlineIngress(name, host string, tlsSecret string) *extensions.Ingress {
+	ing := &extensions.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: extensions.IngressSpec{
+			Rules: []extensions.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: extensions.IngressRuleValue{
+						HTTP: &extensions.HTTPIngressRuleValue{
+							Paths: []extensions.HTTPIngressPath{
+								{
+									Path: "/",
+									Backend: extensions.IngressBackend{
+										ServiceName: "web",
+										ServicePort: intstr.FromInt(80),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if tlsSecret != "" {
+		ing.Spec.TLS = []extensions.IngressTLS{{Hosts: []string{host}, SecretName: tlsSecret}}
+	}
+	return ing
+}
+
+func TestOfflineController(t *testing.T) {
+	objects := []runtime.Object{
+		&api_v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-config", Namespace: "kube-system"},
+			Data:       map[string]string{"server-names-hash-bucket-size": "256"},
+		},
+		&api_v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: api_v1.ServiceSpec{
+				Ports: []api_v1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)}},
+			},
+		},
+		&api_v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Subsets: []api_v1.EndpointSubset{
+				{
+					Addresses: []api_v1.EndpointAddress{{IP: "10.0.0.1"}},
+					Ports:     []api_v1.EndpointPort{{Name: "http", Port: 8080}},
+				},
+			},
+		},
+		offlineIngress("web", "web.example.com", ""),
+		offlineIngress("secure", "secure.example.com", "missing"),
+	}
+
+	t.Run("renders the main config and all ingresses", func(t *testing.T) {
+		assert := assert.New(t)
+		mcs := memory.NewMainConfigStorage()
+		scs := memory.NewServerConfigStorage()
+		recorder := record.NewFakeRecorder(10)
+		ch, _ := collision.NewCollisionHandler(collision.PolicyMerge, false)
+
+		c, err := NewOfflineController(objects, "kube-system/nginx-config", "", "", nil, true, ch, recorder, mcs, scs)
+		if !assert.NoError(err) {
+			return
+		}
+		assert.NoError(c.Render())
+
+		mainConfig, _ := mcs.Get()
+		if assert.NotNil(mainConfig) {
+			assert.Contains(string(mainConfig.Config), "server_names_hash_bucket_size 256;")
+		}
+
+		servers, _ := scs.List()
+		if assert.Len(servers, 1) {
+			assert.Equal("web.example.com", servers[0].Name)
+			assert.Contains(string(servers[0].Config), "server 10.0.0.1:8080;")
+		}
+
+		close(recorder.Events)
+		events := []string{}
+		for event := range recorder.Events {
+			events = append(events, event)
+		}
+		if assert.Len(events, 1) {
+			assert.True(strings.HasPrefix(events[0], "Warning SecretNotFound"), events[0])
+		}
+	})
+
+	t.Run("fails for a missing ConfigMap", func(t *testing.T) {
+		ch, _ := collision.NewCollisionHandler(collision.PolicyMerge, false)
+		c, err := NewOfflineController(objects, "default/missing", "", "", nil, true, ch, &record.FakeRecorder{},
+			memory.NewMainConfigStorage(), memory.NewServerConfigStorage())
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.EqualError(t, c.Render(), "ConfigMap default/missing not found")
+	})
+
+	t.Run("uses the given templates", func(t *testing.T) {
+		assert := assert.New(t)
+		mcs := memory.NewMainConfigStorage()
+		scs := memory.NewServerConfigStorage()
+		ch, _ := collision.NewCollisionHandler(collision.PolicyMerge, false)
+
+		c, err := NewOfflineController(objects, "", "# main", "# server {{.Name}}", nil, true, ch, &record.FakeRecorder{}, mcs, scs)
+		if !assert.NoError(err) {
+			return
+		}
+		assert.NoError(c.Render())
+
+		mainConfig, _ := mcs.Get()
+		if assert.NotNil(mainConfig) {
+			assert.Equal("# main", string(mainConfig.Config))
+		}
+		servers, _ := scs.List()
+		if assert.Len(servers, 1) {
+			assert.Equal("# server web.example.com", string(servers[0].Config))
+		}
+	})
+
+	t.Run("rejects unsupported objects", func(t *testing.T) {
+		_, err := NewOfflineController([]runtime.Object{&api_v1.Pod{}}, "", "", "", nil, true, nil, &record.FakeRecorder{},
+			memory.NewMainConfigStorage(), memory.NewServerConfigStorage())
+		assert.EqualError(t, err, "unsupported object type *v1.Pod")
+	})
+}
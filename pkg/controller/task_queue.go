@@ -1,59 +1,87 @@
 package controller
 
 import (
+	"expvar"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
 )
 
-// TaskQueue manages a work queue through an independent worker that
-// invokes the given sync function for every work item inserted.
+// syncFailures counts the keys each task queue gave up on, published with expvar
+var syncFailures = expvar.NewMap("sync_failures")
+
+// backoff of failed items, the delay doubles with every failed retry
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 5 * time.Minute
+)
+
+// TaskQueue manages a rate limited work queue through independent workers that
+// invoke the given sync function for every work item inserted.
 type TaskQueue interface {
-	Run(period time.Duration, stopCh <-chan struct{})
+	// Run starts the given number of workers and blocks until stopCh is closed
+	Run(workers int, stopCh <-chan struct{})
 	// Enqueue enqueues ns/name of the given api object in the task queue.
 	Enqueue(obj interface{})
 	EnqueueKey(key string)
-	Requeue(key string, err error)
-	RequeueAfter(key string, err error, after time.Duration)
-	// Shutdown shuts down the work queue and waits for the worker to ACK
+	// Shutdown shuts down the work queue and waits for the workers to ACK
 	Shutdown()
 }
 
 type taskQueue struct {
-	// queue is the work queue the worker polls
-	queue *workqueue.Type
-	// sync is called for each item in the queue
-	sync func(string)
-	// workerDone is closed when the worker exits
-	workerDone chan struct{}
-	log        *log.Entry
+	name string
+	// queue is the work queue the workers poll,
+	// failed items are requeued with an exponential per item backoff
+	queue workqueue.RateLimitingInterface
+	// sync is called for each item in the queue,
+	// the item is requeued if an error is returned
+	sync func(string) error
+	// giveUp is called when an item failed more than maxRetries times,
+	// the item is dropped until it is enqueued again
+	giveUp     func(key string, err error)
+	maxRetries int
+	// workers is done when all workers have exited
+	workers sync.WaitGroup
+	// shutdown guards the queue shutdown, which must only happen once
+	shutdown sync.Once
+	log      *log.Entry
 }
 
 // NewTaskQueue creates a new task queue with the given sync function.
 // The sync function is called for every element inserted into the queue.
-func NewTaskQueue(syncFn func(string), log *log.Entry) TaskQueue {
-	tq := &taskQueue{
-		queue:      workqueue.New(),
+// The queue guarantees that an element is never processed by multiple workers at the same time.
+// Failed elements are retried with an exponential backoff, after maxRetries failed retries
+// the optional giveUpFn is called and the element is dropped. If maxRetries is 0, elements are retried forever.
+func NewTaskQueue(name string, syncFn func(string) error, maxRetries int, giveUpFn func(key string, err error)) TaskQueue {
+	return &taskQueue{
+		name: name,
+		queue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.NewItemExponentialFailureRateLimiter(retryBaseDelay, retryMaxDelay), name),
 		sync:       syncFn,
-		workerDone: make(chan struct{}),
-		log:        log,
-	}
-	if tq.log == nil {
-		tq.log = log.WithField("module", "Generic TaskQueue")
+		giveUp:     giveUpFn,
+		maxRetries: maxRetries,
+		log:        log.WithField("module", name),
 	}
-	return tq
 }
 
-func (t *taskQueue) Run(period time.Duration, stopCh <-chan struct{}) {
-	wait.Until(t.worker, period, stopCh)
+func (t *taskQueue) Run(workers int, stopCh <-chan struct{}) {
+	if workers < 1 {
+		workers = 1
+	}
+	t.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go t.worker()
+	}
+	<-stopCh
+	t.Shutdown()
 }
 
 func (t *taskQueue) Enqueue(obj interface{}) {
 	key, err := keyFunc(obj)
 	if err != nil {
-		log.
+		t.log.
 			WithField("obj", obj).
 			WithError(err).
 			Error("Couldn't get key for object, skipping")
@@ -66,40 +94,52 @@ func (t *taskQueue) EnqueueKey(key string) {
 	t.queue.Add(key)
 }
 
-func (t *taskQueue) Requeue(key string, err error) {
-	log.
-		WithField("key", key).
-		WithError(err).
-		Error("Requeuing")
-	t.queue.Add(key)
+func (t *taskQueue) Shutdown() {
+	t.shutdown.Do(t.queue.ShutDown)
+	t.workers.Wait()
 }
 
-func (t *taskQueue) RequeueAfter(key string, err error, after time.Duration) {
-	log.
-		WithField("key", key).
-		WithError(err).
-		Errorf("Requeuing after %s", after.String())
-	go func(key string, after time.Duration) {
-		time.Sleep(after)
-		t.queue.Add(key)
-	}(key, after)
+// worker processes work in the queue through sync until the queue is shut down.
+func (t *taskQueue) worker() {
+	defer t.workers.Done()
+	for t.processNextItem() {
+	}
 }
 
-func (t *taskQueue) Shutdown() {
-	t.queue.ShutDown()
-	<-t.workerDone
-}
+func (t *taskQueue) processNextItem() bool {
+	key, quit := t.queue.Get()
+	if quit {
+		return false
+	}
+	defer t.queue.Done(key)
 
-// worker processes work in the queue through sync.
-func (t *taskQueue) worker() {
-	for {
-		key, quit := t.queue.Get()
-		if quit {
-			close(t.workerDone)
-			return
+	t.log.WithField("key", key).Debug("Syncing from taskQueue")
+	err := t.sync(key.(string))
+	if err == nil {
+		t.queue.Forget(key)
+		return true
+	}
+
+	retries := t.queue.NumRequeues(key)
+	if t.maxRetries > 0 && retries >= t.maxRetries {
+		t.log.
+			WithField("key", key).
+			WithField("retries", retries).
+			WithError(err).
+			Error("Giving up, waiting for the next change")
+		t.queue.Forget(key)
+		syncFailures.Add(t.name, 1)
+		if t.giveUp != nil {
+			t.giveUp(key.(string), err)
 		}
-		log.WithField("key", key).Debug("Syncing form taskQueue")
-		t.sync(key.(string))
-		t.queue.Done(key)
+		return true
 	}
+
+	t.log.
+		WithField("key", key).
+		WithField("retries", retries).
+		WithError(err).
+		Error("Requeuing")
+	t.queue.AddRateLimited(key)
+	return true
 }
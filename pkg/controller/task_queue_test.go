@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// newTestTaskQueue creates a task queue that retries without delay
+func newTestTaskQueue(syncFn func(string) error, maxRetries int, giveUpFn func(string, error)) *taskQueue {
+	q := NewTaskQueue("TestTaskQueue", syncFn, maxRetries, giveUpFn).(*taskQueue)
+	q.queue = workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, 10*time.Millisecond))
+	return q
+}
+
+func syncFailureCount(name string) int64 {
+	if v, ok := syncFailures.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestTaskQueue(t *testing.T) {
+	t.Run("requeues items until sync succeeds", func(t *testing.T) {
+		assert := assert.New(t)
+		synced := make(chan string, 10)
+		calls := 0
+		q := newTestTaskQueue(func(key string) error {
+			calls++
+			synced <- key
+			if calls < 3 {
+				return errors.New("test")
+			}
+			return nil
+		}, 0, nil)
+		stopCh := make(chan struct{})
+		go q.Run(2, stopCh)
+		defer close(stopCh)
+
+		q.EnqueueKey("default/ing1")
+		for i := 0; i < 3; i++ {
+			select {
+			case key := <-synced:
+				assert.Equal("default/ing1", key)
+			case <-time.After(5 * time.Second):
+				t.Fatal("item was not requeued")
+			}
+		}
+		select {
+		case <-synced:
+			t.Error("item requeued after successful sync")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		assert := assert.New(t)
+		syncErr := errors.New("test")
+		calls := 0
+		gaveUp := make(chan error, 1)
+		q := newTestTaskQueue(func(key string) error {
+			calls++
+			return syncErr
+		}, 3, func(key string, err error) {
+			assert.Equal("default/ing1", key)
+			gaveUp <- err
+		})
+		failures := syncFailureCount("TestTaskQueue")
+		stopCh := make(chan struct{})
+		go q.Run(1, stopCh)
+
+		q.EnqueueKey("default/ing1")
+		select {
+		case err := <-gaveUp:
+			assert.Equal(syncErr, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("queue did not give up")
+		}
+		close(stopCh)
+		q.Shutdown()
+
+		assert.Equal(4, calls)
+		assert.Equal(0, q.queue.NumRequeues("default/ing1"))
+		assert.Equal(failures+1, syncFailureCount("TestTaskQueue"))
+	})
+
+	t.Run("processes items in parallel", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		release := make(chan struct{})
+		q := newTestTaskQueue(func(key string) error {
+			wg.Done()
+			<-release
+			return nil
+		}, 0, nil)
+		stopCh := make(chan struct{})
+		go q.Run(2, stopCh)
+
+		q.EnqueueKey("default/ing1")
+		q.EnqueueKey("default/ing2")
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("items were not processed in parallel")
+		}
+		close(release)
+		close(stopCh)
+	})
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"io/ioutil"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// templatePollInterval is the interval in which the template files are checked for changes
+const templatePollInterval = 10 * time.Second
+
+// templateWatcher polls the template files, as files mounted from ConfigMaps
+// are replaced by the kubelet instead of being modified
+type templateWatcher struct {
+	mainTemplateFile    string
+	ingressTemplateFile string
+	// content of the files at the last check
+	mainTemplate    string
+	ingressTemplate string
+	// update is called when the content of the files changed
+	update func(mainTemplate, ingressTemplate string) error
+	log    *log.Entry
+}
+
+// newTemplateWatcher creates a watcher for the given template files, empty file names are ignored
+func newTemplateWatcher(mainTemplateFile, ingressTemplateFile string, update func(mainTemplate, ingressTemplate string) error) *templateWatcher {
+	return &templateWatcher{
+		mainTemplateFile:    mainTemplateFile,
+		ingressTemplateFile: ingressTemplateFile,
+		update:              update,
+		log:                 log.WithField("module", "TemplateWatcher"),
+	}
+}
+
+// Load reads the template files and calls the update function if their content changed.
+// A failed update is not retried until the content changes again.
+func (w *templateWatcher) Load() error {
+	mainTemplate, err := readTemplateFile(w.mainTemplateFile)
+	if err != nil {
+		return err
+	}
+	ingressTemplate, err := readTemplateFile(w.ingressTemplateFile)
+	if err != nil {
+		return err
+	}
+	if mainTemplate == w.mainTemplate && ingressTemplate == w.ingressTemplate {
+		return nil
+	}
+
+	w.log.
+		WithField("main-template", w.mainTemplateFile).
+		WithField("ingress-template", w.ingressTemplateFile).
+		Info("Templates changed, updating")
+	w.mainTemplate, w.ingressTemplate = mainTemplate, ingressTemplate
+	return w.update(mainTemplate, ingressTemplate)
+}
+
+// Run checks the template files for changes until stopCh is closed
+func (w *templateWatcher) Run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := w.Load(); err != nil {
+				w.log.WithError(err).Error("Error updating templates")
+			}
+		}
+	}
+}
+
+func readTemplateFile(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	content, err := ioutil.ReadFile(name)
+	return string(content), err
+}
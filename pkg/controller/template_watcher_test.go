@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mainTemplateFile := filepath.Join(dir, "nginx.conf.tmpl")
+	if err = ioutil.WriteFile(mainTemplateFile, []byte("# main"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := []string{}
+	var updateErr error
+	w := newTemplateWatcher(mainTemplateFile, "", func(mainTemplate, ingressTemplate string) error {
+		updates = append(updates, mainTemplate+"|"+ingressTemplate)
+		return updateErr
+	})
+
+	t.Run("updates the templates when the files change", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.NoError(w.Load())
+		assert.NoError(w.Load())
+		assert.Equal([]string{"# main|"}, updates)
+
+		assert.NoError(ioutil.WriteFile(mainTemplateFile, []byte("# changed"), 0600))
+		assert.NoError(w.Load())
+		assert.Equal([]string{"# main|", "# changed|"}, updates)
+	})
+
+	t.Run("does not retry failed updates of unchanged files", func(t *testing.T) {
+		assert := assert.New(t)
+		updates = nil
+		updateErr = fmt.Errorf("invalid")
+
+		assert.NoError(ioutil.WriteFile(mainTemplateFile, []byte("{{"), 0600))
+		assert.EqualError(w.Load(), "invalid")
+		assert.NoError(w.Load())
+		assert.Equal([]string{"{{|"}, updates)
+	})
+
+	t.Run("returns read errors", func(t *testing.T) {
+		assert.Error(t, newTemplateWatcher(filepath.Join(dir, "missing"), "", nil).Load())
+	})
+}
@@ -22,7 +22,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
 	api_v1 "k8s.io/client-go/pkg/api/v1"
 	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
@@ -35,46 +34,67 @@ func compareLinks(l1, l2 string) bool {
 	return l1 == l2 && l1 != ""
 }
 
-// StoreToIngressLister makes a Store that lists Ingress.
+// ingressServiceIndex indexes Ingress objects by the "namespace/name" keys of their backend services
+const ingressServiceIndex = "service"
+
+// ingressServiceIndexFunc returns the keys of all services referenced by the Ingress
+func ingressServiceIndexFunc(obj interface{}) ([]string, error) {
+	ing, ok := obj.(*extensions.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("object is no Ingress: %T", obj)
+	}
+
+	services := map[string]bool{}
+	if ing.Spec.Backend != nil {
+		services[ing.Spec.Backend.ServiceName] = true
+	}
+	for _, rules := range ing.Spec.Rules {
+		if rules.IngressRuleValue.HTTP == nil {
+			continue
+		}
+		for _, p := range rules.IngressRuleValue.HTTP.Paths {
+			services[p.Backend.ServiceName] = true
+		}
+	}
+
+	keys := []string{}
+	for service := range services {
+		keys = append(keys, ing.Namespace+"/"+service)
+	}
+	return keys, nil
+}
+
+// StoreToIngressLister makes a Indexer that lists Ingress.
 // TODO: Move this to cache/listers post 1.1.
 type StoreToIngressLister struct {
-	cache.Store
+	cache.Indexer
 }
 
 // List lists all Ingress' in the store.
 func (s *StoreToIngressLister) List() (ing extensions.IngressList, err error) {
-	for _, m := range s.Store.List() {
+	for _, m := range s.Indexer.List() {
 		ing.Items = append(ing.Items, *(m.(*extensions.Ingress)))
 	}
 	return ing, nil
 }
 
 // GetServiceIngress gets all the Ingress' that have rules pointing to a service.
-// Note that this ignores services without the right nodePorts.
 func (s *StoreToIngressLister) GetServiceIngress(svc *api_v1.Service) (ings []extensions.Ingress, err error) {
-	for _, m := range s.Store.List() {
-		ing := *m.(*extensions.Ingress)
-		if ing.Namespace != svc.Namespace {
-			continue
-		}
-		if ing.Spec.Backend != nil {
-			if ing.Spec.Backend.ServiceName == svc.Name {
-				ings = append(ings, ing)
-			}
-		}
-		for _, rules := range ing.Spec.Rules {
-			if rules.IngressRuleValue.HTTP == nil {
-				continue
-			}
-			for _, p := range rules.IngressRuleValue.HTTP.Paths {
-				if p.Backend.ServiceName == svc.Name {
-					ings = append(ings, ing)
-				}
-			}
-		}
+	return s.GetServiceKeyIngress(svc.Namespace + "/" + svc.Name)
+}
+
+// GetServiceKeyIngress gets all the Ingress' that have rules pointing to the service with the given key,
+// every Ingress is returned only once
+func (s *StoreToIngressLister) GetServiceKeyIngress(svcKey string) (ings []extensions.Ingress, err error) {
+	objs, err := s.Indexer.ByIndex(ingressServiceIndex, svcKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range objs {
+		ings = append(ings, *m.(*extensions.Ingress))
 	}
 	if len(ings) == 0 {
-		err = fmt.Errorf("No ingress for service %v", svc.Name)
+		err = fmt.Errorf("No ingress for service %v", svcKey)
 	}
 	return
 }
@@ -99,37 +119,15 @@ type StoreToEndpointLister struct {
 
 // GetServiceEndpoints returns the endpoints of a service, matched on service name.
 func (s *StoreToEndpointLister) GetServiceEndpoints(svc *api_v1.Service) (ep api_v1.Endpoints, err error) {
-	for _, m := range s.Store.List() {
-		ep = *m.(*api_v1.Endpoints)
-		if svc.Name == ep.Name && svc.Namespace == ep.Namespace {
-			return ep, nil
-		}
+	obj, exists, err := s.Store.GetByKey(svc.Namespace + "/" + svc.Name)
+	if err != nil {
+		return
 	}
-	err = fmt.Errorf("could not find endpoints for service: %v", svc.Name)
-	return
-}
-
-// FindPort locates the container port for the given pod and portName.  If the
-// targetPort is a number, use that.  If the targetPort is a string, look that
-// string up in all named ports in all containers in the target pod.  If no
-// match is found, fail.
-func FindPort(pod *api_v1.Pod, svcPort *api_v1.ServicePort) (int32, error) {
-	portName := svcPort.TargetPort
-	switch portName.Type {
-	case intstr.String:
-		name := portName.StrVal
-		for _, container := range pod.Spec.Containers {
-			for _, port := range container.Ports {
-				if port.Name == name && port.Protocol == svcPort.Protocol {
-					return port.ContainerPort, nil
-				}
-			}
-		}
-	case intstr.Int:
-		return int32(portName.IntValue()), nil
+	if !exists {
+		err = fmt.Errorf("could not find endpoints for service: %v", svc.Name)
+		return
 	}
-
-	return 0, fmt.Errorf("no suitable port for manifest: %s", pod.UID)
+	return *obj.(*api_v1.Endpoints), nil
 }
 
 // NewListWatchFromClient creates a new ListWatch from the specified client, resource, namespace and label selector.
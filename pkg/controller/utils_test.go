@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	extensions "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func ingressForServices(name string, services ...string) *extensions.Ingress {
+	paths := []extensions.HTTPIngressPath{}
+	for _, service := range services {
+		paths = append(paths, extensions.HTTPIngressPath{
+			Path: "/" + service,
+			Backend: extensions.IngressBackend{
+				ServiceName: service,
+				ServicePort: intstr.FromInt(80),
+			},
+		})
+	}
+	return &extensions.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: extensions.IngressSpec{
+			Backend: &extensions.IngressBackend{ServiceName: services[0]},
+			Rules: []extensions.IngressRule{
+				extensions.IngressRule{
+					Host: "one.example.com",
+					IngressRuleValue: extensions.IngressRuleValue{
+						HTTP: &extensions.HTTPIngressRuleValue{Paths: paths},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestStoreToIngressLister(t *testing.T) {
+	lister := StoreToIngressLister{cache.NewIndexer(keyFunc, cache.Indexers{ingressServiceIndex: ingressServiceIndexFunc})}
+	lister.Add(ingressForServices("ing1", "svc1", "svc1", "svc2"))
+	lister.Add(ingressForServices("ing2", "svc2"))
+
+	t.Run("returns every ingress only once", func(t *testing.T) {
+		assert := assert.New(t)
+		ings, err := lister.GetServiceIngress(&api_v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "default"},
+		})
+		assert.NoError(err)
+		if assert.Len(ings, 1) {
+			assert.Equal("ing1", ings[0].Name)
+		}
+
+		ings, err = lister.GetServiceKeyIngress("default/svc2")
+		assert.NoError(err)
+		assert.Len(ings, 2)
+	})
+
+	t.Run("returns an error if no ingress uses the service", func(t *testing.T) {
+		_, err := lister.GetServiceKeyIngress("other/svc1")
+		assert.Error(t, err)
+	})
+}
+
+func TestStoreToEndpointLister(t *testing.T) {
+	lister := StoreToEndpointLister{cache.NewStore(keyFunc)}
+	endps := &api_v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "default"}}
+	lister.Add(endps)
+
+	ep, err := lister.GetServiceEndpoints(&api_v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "default"}})
+	assert.NoError(t, err)
+	assert.Equal(t, *endps, ep)
+
+	_, err = lister.GetServiceEndpoints(&api_v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "other"}})
+	assert.Error(t, err)
+}
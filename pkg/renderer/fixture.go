@@ -0,0 +1,126 @@
+package renderer
+
+import (
+	"github.com/thetechnick/nginx-ingress/pkg/collision"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// templateFixtures returns the data templates are rendered with before they are used,
+// it enables the optional settings so that most template branches are executed
+func templateFixtures() (*MainConfigTemplateData, *collision.MergedIngressConfig) {
+	mainCfg := config.NewDefaultConfig()
+	mainCfg.MainHTTPSnippets = []string{"# http snippet"}
+	mainCfg.MainServerNamesHashBucketSize = "64"
+	mainCfg.MainLogFormat = "$remote_addr"
+	mainCfg.MainServerSSLProtocols = "TLSv1.2"
+	mainCfg.MainServerSSLCiphers = "HIGH:!aNULL:!MD5"
+	mainCfg.MainServerSSLPreferServerCiphers = true
+	mainCfg.MainServerSSLDHParamFile = "dhparam"
+	mainCfg.GzipTypes = []string{"text/css", "application/json"}
+	mainCfg.UseBrotli = true
+	mainCfg.MainWorkerRLimitNofile = "65536"
+	mainCfg.MainWorkerCPUAffinity = "auto"
+	mainCfg.MainKeepaliveRequests = "1000"
+	mainData := MainConfigTemplateDataFromIngressConfig(mainCfg)
+	mainData.HealthStatus = true
+
+	upstream := config.Upstream{
+		Name: "default-fixture-example.com-svc",
+		UpstreamServers: []config.UpstreamServer{
+			{Address: "10.0.0.1", Port: "8080"},
+		},
+	}
+	server := &config.Server{
+		Name:              "example.com",
+		Upstreams:         []config.Upstream{upstream},
+		SSL:               true,
+		SSLCertificate:    "/etc/nginx/ssl/example.com.pem",
+		SSLCertificateKey: "/etc/nginx/ssl/example.com.pem",
+		Files:             []*pb.File{},
+		ServerSnippets:    []string{"# server snippet"},
+		HTTP2:             true,
+		H2C:               true,
+		RedirectToHTTPS:   true,
+		ProxyProtocol:     true,
+		HSTS:              true,
+		HSTSMaxAge:        2592000,
+		ProxyHideHeaders:  []string{"X-Powered-By"},
+		ProxyPassHeaders:  []string{"Server"},
+		RealIPHeader:      "X-Forwarded-For",
+		SetRealIPFrom:     []string{"10.0.0.0/8"},
+		RealIPRecursive:   true,
+		Gzip:              true,
+		GzipLevel:         5,
+		GzipMinLength:     "256",
+		GzipTypes:         []string{"text/css", "application/json"},
+		Brotli:            true,
+		BrotliModule:      true,
+		Locations: []config.Location{
+			{
+				Path:                       "/",
+				Upstream:                   upstream,
+				LocationSnippets:           []string{"# location snippet"},
+				ProxyConnectTimeout:        "60s",
+				ProxyReadTimeout:           "60s",
+				ClientMaxBodySize:          "1m",
+				Websocket:                  true,
+				Rewrite:                    "/",
+				BackendProtocol:            config.BackendProtocolHTTPS,
+				ProxyBuffering:             true,
+				ProxyBuffers:               "8 4k",
+				ProxyBufferSize:            "4k",
+				ProxyMaxTempFileSize:       "1024m",
+				BasicAuth:                  "fixture",
+				BasicAuthUserFile:          "/etc/nginx/auth/fixture",
+				ProxySSLVerify:             true,
+				ProxySSLName:               "backend.example.com",
+				ProxySSLTrustedCertificate: "/etc/nginx/ssl/default-backend-ca.pem",
+				ProxySSLCertificate:        "/etc/nginx/ssl/default-backend-client.pem",
+			},
+			{
+				Path:                       "/grpc",
+				Upstream:                   upstream,
+				ProxyConnectTimeout:        "60s",
+				ProxyReadTimeout:           "60s",
+				ClientMaxBodySize:          "1m",
+				BackendProtocol:            config.BackendProtocolGRPCS,
+				ProxySSLVerify:             true,
+				ProxySSLName:               "backend.example.com",
+				ProxySSLTrustedCertificate: "/etc/nginx/ssl/default-backend-ca.pem",
+				ProxySSLCertificate:        "/etc/nginx/ssl/default-backend-client.pem",
+			},
+			{
+				Path:                "/fcgi",
+				Upstream:            upstream,
+				ProxyConnectTimeout: "60s",
+				ProxyReadTimeout:    "60s",
+				ClientMaxBodySize:   "1m",
+				BackendProtocol:     config.BackendProtocolFCGI,
+				FastCGIIndex:        "index.php",
+				FastCGIParams:       map[string]string{"SCRIPT_FILENAME": "/var/www/html$fastcgi_script_name"},
+			},
+			{
+				Path:                "/uwsgi",
+				Upstream:            upstream,
+				ProxyConnectTimeout: "60s",
+				ProxyReadTimeout:    "60s",
+				ClientMaxBodySize:   "1m",
+				BackendProtocol:     config.BackendProtocolUWSGI,
+			},
+		},
+	}
+	ing := &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "fixture",
+			Namespace:   "default",
+			Annotations: map[string]string{"example.com/fixture": "true"},
+		},
+	}
+	return mainData, &collision.MergedIngressConfig{
+		Ingress: []*v1beta1.Ingress{ing},
+		Server:  server,
+	}
+}
@@ -0,0 +1,141 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// templateFuncs returns the helper functions available in the templates.
+// ingresses are the ingress objects of the rendered server, oldest first,
+// they are empty when the main config is rendered.
+func templateFuncs(ingresses []*v1beta1.Ingress) template.FuncMap {
+	return template.FuncMap{
+		"quote":    quote,
+		"escape":   escape,
+		"join":     join,
+		"dict":     dict,
+		"mapBlock": mapBlock,
+		"default":  defaultValue,
+		"ingresses": func() []*v1beta1.Ingress {
+			return ingresses
+		},
+		"annotation": func(key string) string {
+			return annotation(ingresses, key)
+		},
+	}
+}
+
+// escape escapes backslashes, quotes and control characters,
+// so the value can be used inside single or double quoted nginx strings
+func escape(value string) string {
+	var buffer bytes.Buffer
+	for _, c := range value {
+		switch c {
+		case '\\', '"', '\'':
+			buffer.WriteRune('\\')
+			buffer.WriteRune(c)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		default:
+			buffer.WriteRune(c)
+		}
+	}
+	return buffer.String()
+}
+
+// quote returns the value as escaped double quoted nginx string,
+// nginx variables in the value are still evaluated by directives supporting them
+func quote(value string) string {
+	return `"` + escape(value) + `"`
+}
+
+// join concatenates the values with the separator, the separator is the first argument
+// so that values can be piped into it: {{.ProxyHideHeaders | join " "}}
+func join(sep string, values []string) string {
+	return strings.Join(values, sep)
+}
+
+// dict creates a map from key value pairs: {{dict "key1" "value1" "key2" "value2"}}
+func dict(pairs ...string) (map[string]string, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict expects key value pairs, got %d arguments", len(pairs))
+	}
+	m := map[string]string{}
+	for i := 0; i < len(pairs); i += 2 {
+		m[pairs[i]] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// mapBlock renders a nginx map block setting the variable depending on the source value,
+// the entries are rendered ordered by key, a "default" key sets the default value:
+// {{mapBlock "$http_x_tenant" "$tenant_upstream" (dict "a" "upstream-a" "default" "upstream-b")}}
+func mapBlock(source, variable string, entries map[string]string) (string, error) {
+	for _, v := range []string{source, variable} {
+		if !strings.HasPrefix(v, "$") || strings.ContainsAny(v, " \t\n;{}\"'") {
+			return "", fmt.Errorf("mapBlock: %q is no nginx variable", v)
+		}
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "map %s %s {\n", source, variable)
+	for _, key := range keys {
+		if key == "default" {
+			fmt.Fprintf(&buffer, "\tdefault %s;\n", quote(entries[key]))
+			continue
+		}
+		fmt.Fprintf(&buffer, "\t%s %s;\n", quote(key), quote(entries[key]))
+	}
+	buffer.WriteString("}")
+	return buffer.String(), nil
+}
+
+// defaultValue returns the value, or def if the value is empty,
+// so that it can be used in pipelines: {{.ProxyBuffers | default "8 4k"}}
+func defaultValue(def interface{}, value interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return def
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return def
+		}
+	default:
+		if reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface()) {
+			return def
+		}
+	}
+	return value
+}
+
+// annotation returns the value of the annotation of the oldest ingress setting it
+func annotation(ingresses []*v1beta1.Ingress, key string) string {
+	for _, ing := range ingresses {
+		if value, ok := ing.Annotations[key]; ok {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Run("escape and quote", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal(`a \"b\" \'c\' \\d\n`, escape("a \"b\" 'c' \\d\n"))
+		assert.Equal(`"realm \"x\""`, quote(`realm "x"`))
+		assert.Equal(`""`, quote(""))
+	})
+
+	t.Run("join", func(t *testing.T) {
+		assert.Equal(t, "a b", join(" ", []string{"a", "b"}))
+	})
+
+	t.Run("dict", func(t *testing.T) {
+		assert := assert.New(t)
+		m, err := dict("a", "1", "b", "2")
+		assert.NoError(err)
+		assert.Equal(map[string]string{"a": "1", "b": "2"}, m)
+
+		_, err = dict("a")
+		assert.Error(err)
+	})
+
+	t.Run("mapBlock", func(t *testing.T) {
+		assert := assert.New(t)
+		block, err := mapBlock("$http_x_tenant", "$tenant", map[string]string{
+			"b":       "upstream-b",
+			"default": "none",
+			"a;":      "upstream-a",
+		})
+		assert.NoError(err)
+		assert.Equal("map $http_x_tenant $tenant {\n"+
+			"\t\"a;\" \"upstream-a\";\n"+
+			"\t\"b\" \"upstream-b\";\n"+
+			"\tdefault \"none\";\n"+
+			"}", block)
+
+		_, err = mapBlock("$host", "tenant; include x", nil)
+		assert.Error(err)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal("8 4k", defaultValue("8 4k", ""))
+		assert.Equal("16 8k", defaultValue("8 4k", "16 8k"))
+		assert.Equal(int64(10), defaultValue(int64(10), int64(0)))
+		assert.Equal([]string{"a"}, defaultValue([]string{"a"}, []string{}))
+		assert.Equal("x", defaultValue("x", nil))
+	})
+
+	t.Run("annotation uses the oldest ingress setting it", func(t *testing.T) {
+		assert := assert.New(t)
+		ingresses := []*v1beta1.Ingress{
+			{ObjectMeta: metav1.ObjectMeta{Name: "old", Annotations: map[string]string{"a": "old"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "new", Annotations: map[string]string{"a": "new", "b": "new"}}},
+		}
+		assert.Equal("old", annotation(ingresses, "a"))
+		assert.Equal("new", annotation(ingresses, "b"))
+		assert.Equal("", annotation(ingresses, "c"))
+	})
+
+	t.Run("are available in server templates", func(t *testing.T) {
+		assert := assert.New(t)
+		r := NewRenderer()
+		_, fixture := templateFixtures()
+		err := r.UpdateTemplates("", `{{range ingresses}}# {{.Name}}{{end}} {{annotation "example.com/fixture" | default "false"}}`+
+			` {{.ProxyHideHeaders | join ","}} {{quote .Name}}`)
+		if !assert.NoError(err) {
+			return
+		}
+
+		sc, err := r.RenderServerConfig(fixture)
+		if assert.NoError(err) {
+			assert.Equal(`# fixture true X-Powered-By "example.com"`, string(sc.Config))
+		}
+	})
+}
@@ -0,0 +1,377 @@
+package renderer
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetechnick/nginx-ingress/pkg/collision"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/errors"
+	"github.com/thetechnick/nginx-ingress/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// fuzzRuns is the number of random configs rendered by each fuzz test
+const fuzzRuns = 500
+
+// fuzzAlphabet contains the characters with a special meaning in the nginx config syntax
+var fuzzAlphabet = []rune("aZ09/-_.=,:~*$#;{}\"'\\ \t\n")
+
+func randomValue(rnd *rand.Rand) string {
+	runes := make([]rune, rnd.Intn(12))
+	for i := range runes {
+		runes[i] = fuzzAlphabet[rnd.Intn(len(fuzzAlphabet))]
+	}
+	return string(runes)
+}
+
+// randomScalar returns a random number, size, offset, time or buffer value built from
+// numbers with units and separators, like "1h 30m", "8 4k" or "10s;"
+func randomScalar(rnd *rand.Rand) string {
+	numbers := []string{"0", "1", "30", "512"}
+	units := []string{"", "k", "m", "g", "s", "ms", "h", "M"}
+	separators := []string{"", " ", "  ", "\t", "\n", ";", "}"}
+	value := ""
+	for i := rnd.Intn(3); i >= 0; i-- {
+		value += numbers[rnd.Intn(len(numbers))] + units[rnd.Intn(len(units))] + separators[rnd.Intn(len(separators))]
+	}
+	return value
+}
+
+// fuzzValue returns a random value for an annotation or ConfigMap key
+// and a harmless value rendering the same directives, if the random value is accepted
+type fuzzValue func(rnd *rand.Rand) (value, safe string)
+
+func scalar(safe string) fuzzValue {
+	return func(rnd *rand.Rand) (string, string) {
+		return randomScalar(rnd), safe
+	}
+}
+
+func randomList(element string) fuzzValue {
+	return func(rnd *rand.Rand) (string, string) {
+		values := []string{}
+		for i := rnd.Intn(3); i >= 0; i-- {
+			values = append(values, randomValue(rnd))
+		}
+		// the random values may contain the delimiter
+		value := strings.Join(values, ",")
+		safe := make([]string, len(strings.Split(value, ",")))
+		for i := range safe {
+			safe[i] = element
+		}
+		return value, strings.Join(safe, ",")
+	}
+}
+
+var fuzzAnnotations = map[string]fuzzValue{
+	"nginx.org/auth-basic": func(rnd *rand.Rand) (string, string) {
+		value := randomValue(rnd)
+		if value == "" || value == `""` {
+			return value, ""
+		}
+		return value, "realm"
+	},
+	"nginx.org/rewrites": func(rnd *rand.Rand) (string, string) {
+		return "serviceName=fuzz-svc rewrite=" + randomValue(rnd), "serviceName=fuzz-svc rewrite=/safe"
+	},
+	"nginx.org/backend-protocol": func(rnd *rand.Rand) (string, string) {
+		return "HTTPS", "HTTPS"
+	},
+	"nginx.org/proxy-ssl-name": func(rnd *rand.Rand) (string, string) {
+		return randomValue(rnd), "backend"
+	},
+	"nginx.org/proxy-hide-headers": randomList("X-Hide"),
+	"nginx.org/proxy-pass-headers": randomList("X-Pass"),
+	"nginx.org/gzip-types":         randomList("text/css"),
+
+	"nginx.org/proxy-connect-timeout":    scalar("10s"),
+	"nginx.org/proxy-read-timeout":       scalar("10s"),
+	"nginx.org/client-max-body-size":     scalar("1m"),
+	"nginx.org/proxy-buffers":            scalar("8 4k"),
+	"nginx.org/proxy-buffer-size":        scalar("4k"),
+	"nginx.org/proxy-max-temp-file-size": scalar("1m"),
+	"nginx.org/gzip-min-length":          scalar("20"),
+}
+
+func fuzzIngress(annotations map[string]string, path string) *v1beta1.Ingress {
+	paths := []v1beta1.HTTPIngressPath{
+		{Path: "/", Backend: v1beta1.IngressBackend{ServiceName: "fuzz-svc", ServicePort: intstr.FromInt(80)}},
+	}
+	if path != "" {
+		paths = append(paths, v1beta1.HTTPIngressPath{
+			Path:    path,
+			Backend: v1beta1.IngressBackend{ServiceName: "fuzz-svc", ServicePort: intstr.FromInt(80)},
+		})
+	}
+	annotations["nginx.org/auth-basic-user-secret"] = "fuzz-users"
+	return &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "fuzz",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC)),
+			Annotations:       annotations,
+		},
+		Spec: v1beta1.IngressSpec{
+			Rules: []v1beta1.IngressRule{
+				{
+					Host: "fuzz.example.com",
+					IngressRuleValue: v1beta1.IngressRuleValue{
+						HTTP: &v1beta1.HTTPIngressRuleValue{Paths: paths},
+					},
+				},
+			},
+		},
+	}
+}
+
+// rejectedKeys returns the annotations and ConfigMap keys skipped by the parser
+func rejectedKeys(warning error) map[string]bool {
+	rejected := map[string]bool{}
+	if ctx, ok := warning.(errors.ErrObjectContext); ok {
+		warning = ctx.WrappedError()
+	}
+	if errs, ok := warning.(config.ValidationError); ok {
+		for _, err := range errs {
+			switch e := err.(type) {
+			case *config.IngressAnnotationError:
+				rejected[e.Annotation] = true
+			case *config.ConfigMapKeyError:
+				rejected[e.Key] = true
+			}
+		}
+	}
+	return rejected
+}
+
+// renderFuzzIngress parses, merges and renders the ingress with the global config like the
+// configurator does, it returns the rendered config and the rejected annotations
+func renderFuzzIngress(t *testing.T, r Renderer, gCfg *config.GlobalConfig, ing *v1beta1.Ingress) (string, map[string]bool) {
+	ingCfg, warning, err := config.NewIngressConfigParser().Parse(ing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	servers, _, err := config.NewServerConfigParser().Parse(
+		*gCfg,
+		*ingCfg,
+		nil,
+		map[string][]string{"fuzz-svc80": {"10.0.0.1:8080"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, server := range servers {
+		for i := range server.Locations {
+			server.Locations[i].BasicAuth = ingCfg.BasicAuth
+			server.Locations[i].BasicAuthUserFile = "/etc/nginx/auth/default-fuzz-users"
+		}
+	}
+
+	merged, err := collision.NewMergingCollisionHandler().Resolve(collision.MergeList{
+		collision.IngressConfig{Ingress: ing, Servers: servers},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := ""
+	for i := range merged {
+		sc, err := r.RenderServerConfig(&merged[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		rendered += string(sc.Config)
+	}
+	return rendered, rejectedKeys(warning)
+}
+
+// directives returns the names of the directives of a rendered config, including nested ones,
+// followed by their number of arguments, like "proxy_read_timeout 1". The config is split
+// like nginx does: on whitespace, ";", "{" and "}" outside of quoted strings
+func directives(t *testing.T, rendered string) []string {
+	directives := []string{}
+	statement, token, inToken, depth := false, []rune{}, false, 0
+	name, args := "", 0
+	flush := func() {
+		if inToken && !statement {
+			name, args = string(token), 0
+			statement = true
+		} else if inToken {
+			args++
+		}
+		token, inToken = token[:0], false
+	}
+
+	runes := []rune(rendered)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			flush()
+		case r == '#' && !inToken:
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == ';' || r == '{' || r == '}':
+			flush()
+			if (r == '}') == statement {
+				t.Fatalf("unexpected %q at offset %d:\n%s", r, i, rendered)
+			}
+			if statement {
+				directives = append(directives, fmt.Sprintf("%s %d", name, args))
+			}
+			statement = false
+			if r == '{' {
+				depth++
+			} else if r == '}' {
+				depth--
+			}
+		case (r == '"' || r == '\'') && !inToken:
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				token = append(token, runes[i])
+			}
+			if i >= len(runes) {
+				t.Fatalf("unterminated string:\n%s", rendered)
+			}
+			inToken = true
+			flush()
+		case r == '\\' && i+1 < len(runes):
+			i++
+			token, inToken = append(token, runes[i]), true
+		default:
+			token, inToken = append(token, r), true
+		}
+	}
+	flush()
+	if statement || depth != 0 {
+		t.Fatalf("unterminated directive or block:\n%s", rendered)
+	}
+	return directives
+}
+
+// TestFuzzIngressAnnotations renders random annotation values and checks that the
+// config contains exactly the directives of a config rendered with harmless values
+func TestFuzzIngressAnnotations(t *testing.T) {
+	r := NewRenderer()
+	rnd := rand.New(rand.NewSource(1))
+
+	for run := 0; run < fuzzRuns; run++ {
+		annotations, safe := map[string]string{}, map[string]string{}
+		for key, value := range fuzzAnnotations {
+			annotations[key], safe[key] = value(rnd)
+		}
+		path := randomValue(rnd)
+		if path != "" {
+			path = "/" + path
+		}
+
+		rendered, rejected := renderFuzzIngress(t, r, config.NewDefaultConfig(), fuzzIngress(annotations, path))
+		for key := range rejected {
+			delete(safe, key)
+		}
+		if _, err := util.ParseLocationPath(path); err == nil {
+			path = "/safe"
+		} else {
+			path = ""
+		}
+		expected, _ := renderFuzzIngress(t, r, config.NewDefaultConfig(), fuzzIngress(safe, path))
+
+		if !assert.Equal(t, directives(t, expected), directives(t, rendered), "annotations: %q, path: %q", annotations, path) {
+			t.Fatalf("rendered config:\n%s\nexpected:\n%s", rendered, expected)
+		}
+	}
+}
+
+// TestFuzzConfigMap renders random ConfigMap values into the main config and a server config
+// and checks that they contain exactly the directives of configs rendered with harmless values
+func TestFuzzConfigMap(t *testing.T) {
+	r := NewRenderer()
+	rnd := rand.New(rand.NewSource(1))
+	keys := map[string]fuzzValue{
+		"log-format": func(rnd *rand.Rand) (string, string) {
+			value := randomValue(rnd)
+			if value == "" {
+				return value, ""
+			}
+			return value, "$remote_addr"
+		},
+		"ssl-ciphers": func(rnd *rand.Rand) (string, string) {
+			value := randomValue(rnd)
+			if strings.Trim(value, "\n") == "" {
+				return value, ""
+			}
+			return value, "HIGH"
+		},
+		"ssl-protocols": func(rnd *rand.Rand) (string, string) {
+			value := randomValue(rnd)
+			if len(strings.Fields(value)) == 0 {
+				return value, ""
+			}
+			return value, "TLSv1.2"
+		},
+		"gzip-types": randomList("text/css"),
+		"access-log-path": func(rnd *rand.Rand) (string, string) {
+			return "/var/log/nginx/" + randomValue(rnd), "/var/log/nginx/access.log"
+		},
+		"worker-cpu-affinity": func(rnd *rand.Rand) (string, string) {
+			return randomValue(rnd), "auto"
+		},
+		"error-log-level": func(rnd *rand.Rand) (string, string) {
+			return randomValue(rnd), "warn"
+		},
+
+		"proxy-connect-timeout":         scalar("10s"),
+		"proxy-read-timeout":            scalar("10s"),
+		"worker-shutdown-timeout":       scalar("10s"),
+		"keepalive-timeout":             scalar("65"),
+		"client-max-body-size":          scalar("1m"),
+		"proxy-buffers":                 scalar("8 4k"),
+		"proxy-buffer-size":             scalar("4k"),
+		"proxy-max-temp-file-size":      scalar("1m"),
+		"gzip-min-length":               scalar("20"),
+		"server-names-hash-bucket-size": scalar("64"),
+		"server-names-hash-max-size":    scalar("512"),
+		"worker-processes":              scalar("2"),
+		"worker-connections":            scalar("1024"),
+		"worker-rlimit-nofile":          scalar("1024"),
+		"keepalive-requests":            scalar("100"),
+	}
+
+	render := func(data map[string]string) (string, map[string]bool) {
+		cfg, warning := config.NewConfigMapParser().Parse(&api_v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-config", Namespace: "default"},
+			Data:       data,
+		})
+		mc, err := r.RenderMainConfig(MainConfigTemplateDataFromIngressConfig(cfg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		server, _ := renderFuzzIngress(t, r, cfg, fuzzIngress(map[string]string{}, ""))
+		return string(mc.Config) + server, rejectedKeys(warning)
+	}
+
+	for run := 0; run < fuzzRuns; run++ {
+		data, safe := map[string]string{}, map[string]string{}
+		for key, value := range keys {
+			data[key], safe[key] = value(rnd)
+		}
+
+		rendered, rejected := render(data)
+		for key := range rejected {
+			delete(safe, key)
+		}
+		expected, _ := render(safe)
+
+		if !assert.Equal(t, directives(t, expected), directives(t, rendered), "data: %q", data) {
+			t.Fatalf("rendered config:\n%s\nexpected:\n%s", rendered, expected)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+//go:build ignore
+// +build ignore
+
+// gen_templates embeds the default templates into templates.go,
+// run it with "go generate" after changing a template
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+)
+
+var templates = []struct {
+	constant string
+	file     string
+}{
+	{"defaultMainConfigTemplate", "nginx.conf.tmpl"},
+	{"defaultIngressTemplate", "ingress.tmpl"},
+}
+
+func main() {
+	var buffer bytes.Buffer
+	fmt.Fprint(&buffer, "// Code generated by gen_templates.go. DO NOT EDIT.\n\npackage renderer\n\n")
+	for _, t := range templates {
+		content, err := ioutil.ReadFile(t.file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(&buffer, "// %s is the embedded %s\nconst %s = %q\n\n", t.constant, t.file, t.constant, content)
+	}
+
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile("templates.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,230 @@
+package renderer
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thetechnick/nginx-ingress/pkg/collision"
+	"github.com/thetechnick/nginx-ingress/pkg/config"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// goldenRuns is the number of times each case is rendered,
+// to detect output that depends on the map iteration order
+const goldenRuns = 20
+
+type goldenCase struct {
+	name      string
+	ingresses []*v1beta1.Ingress
+	tlsCerts  map[string]*pb.File
+	endpoints map[string][]string
+}
+
+func goldenIngress(name string, age time.Duration, annotations map[string]string, host string, paths map[string]string, pathOrder ...string) *v1beta1.Ingress {
+	httpPaths := []v1beta1.HTTPIngressPath{}
+	for _, path := range pathOrder {
+		httpPaths = append(httpPaths, v1beta1.HTTPIngressPath{
+			Path: path,
+			Backend: v1beta1.IngressBackend{
+				ServiceName: paths[path],
+				ServicePort: intstr.FromInt(80),
+			},
+		})
+	}
+	return &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC).Add(-age)),
+			Annotations:       annotations,
+		},
+		Spec: v1beta1.IngressSpec{
+			Rules: []v1beta1.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: v1beta1.IngressRuleValue{
+						HTTP: &v1beta1.HTTPIngressRuleValue{Paths: httpPaths},
+					},
+				},
+			},
+		},
+	}
+}
+
+var goldenCases = []goldenCase{
+	{
+		name: "single-ingress",
+		ingresses: []*v1beta1.Ingress{
+			goldenIngress("cafe", time.Hour, nil, "cafe.example.com", map[string]string{
+				"/":             "coffee-svc",
+				"/tea":          "tea-svc",
+				"/tea/green":    "tea-svc",
+				"/coffee":       "coffee-svc",
+				"/coffee/beans": "beans-svc",
+			}, "/", "/tea", "/coffee", "/tea/green", "/coffee/beans"),
+		},
+		tlsCerts: map[string]*pb.File{
+			"cafe.example.com": {Name: "/etc/nginx/ssl/cafe.example.com.pem", Content: []byte("cert")},
+		},
+		endpoints: map[string][]string{
+			"coffee-svc80": {"10.0.0.1:8080", "10.0.0.2:8080"},
+			"tea-svc80":    {"10.0.1.1:8080"},
+			"beans-svc80":  {"10.0.2.1:8080"},
+		},
+	},
+	{
+		name: "merged-regex-and-prefix",
+		ingresses: []*v1beta1.Ingress{
+			goldenIngress("shop", time.Hour, nil, "shop.example.com", map[string]string{
+				"/":          "web-svc",
+				"/shop":      "shop-svc",
+				"/shop/cart": "cart-svc",
+			}, "/shop", "/", "/shop/cart"),
+			goldenIngress("api", 2*time.Hour, map[string]string{"nginx.org/location-modifier": "~"}, "shop.example.com", map[string]string{
+				"^/api/v[0-9]+/orders": "orders-svc",
+				"^/api/v[0-9]+":        "api-svc",
+				"\\.(css|js)$":         "static-svc",
+			}, "^/api/v[0-9]+/orders", "^/api/v[0-9]+", "\\.(css|js)$"),
+		},
+		endpoints: map[string][]string{
+			"web-svc80":    {"10.0.0.1:8080"},
+			"shop-svc80":   {"10.0.1.1:8080"},
+			"cart-svc80":   {"10.0.2.1:8080"},
+			"orders-svc80": {"10.0.3.1:8080"},
+			"api-svc80":    {"10.0.4.1:8080"},
+			"static-svc80": {"10.0.5.1:8080"},
+		},
+	},
+	{
+		name: "grpc",
+		ingresses: []*v1beta1.Ingress{
+			goldenIngress("grpc", time.Hour, map[string]string{
+				"nginx.org/grpc-services": "greeter-svc,secure-svc",
+				"nginx.org/ssl-services":  "secure-svc",
+			}, "grpc.example.com", map[string]string{
+				"/helloworld.Greeter": "greeter-svc",
+				"/secure.Service":     "secure-svc",
+				"/":                   "web-svc",
+			}, "/helloworld.Greeter", "/secure.Service", "/"),
+		},
+		tlsCerts: map[string]*pb.File{
+			"grpc.example.com": {Name: "/etc/nginx/ssl/grpc.example.com.pem", Content: []byte("cert")},
+		},
+		endpoints: map[string][]string{
+			"greeter-svc80": {"10.0.0.1:50051"},
+			"secure-svc80":  {"10.0.1.1:50051"},
+			"web-svc80":     {"10.0.2.1:8080"},
+		},
+	},
+	{
+		name: "backend-protocols",
+		ingresses: []*v1beta1.Ingress{
+			goldenIngress("app", time.Hour, map[string]string{
+				"nginx.org/backend-protocol":          "HTTPS",
+				"nginx.org/backend-protocol-services": "php-svc=FCGI,python-svc=UWSGI",
+				"nginx.org/proxy-ssl-name":            "web.internal",
+				"nginx.org/fastcgi-index":             "index.php",
+			}, "app.example.com", map[string]string{
+				"/":       "web-svc",
+				"/php":    "php-svc",
+				"/python": "python-svc",
+			}, "/", "/php", "/python"),
+		},
+		endpoints: map[string][]string{
+			"web-svc80":    {"10.0.0.1:8443"},
+			"php-svc80":    {"10.0.1.1:9000"},
+			"python-svc80": {"10.0.2.1:3031"},
+		},
+	},
+	{
+		name: "compression",
+		ingresses: []*v1beta1.Ingress{
+			goldenIngress("api", time.Hour, map[string]string{
+				"nginx.org/gzip-level":      "6",
+				"nginx.org/gzip-min-length": "1k",
+				"nginx.org/gzip-types":      "application/json, text/*",
+			}, "api.example.com", map[string]string{
+				"/": "api-svc",
+			}, "/"),
+			goldenIngress("static", time.Hour, map[string]string{
+				"nginx.org/use-gzip": "false",
+			}, "static.example.com", map[string]string{
+				"/": "static-svc",
+			}, "/"),
+		},
+		endpoints: map[string][]string{
+			"api-svc80":    {"10.0.0.1:8080"},
+			"static-svc80": {"10.0.1.1:8080"},
+		},
+	},
+}
+
+// renderGolden parses, merges and renders the ingresses of the case
+func renderGolden(t *testing.T, r Renderer, c goldenCase) []byte {
+	mergeList := collision.MergeList{}
+	for _, ing := range c.ingresses {
+		ingCfg, _, err := config.NewIngressConfigParser().Parse(ing)
+		if err != nil {
+			t.Fatal(err)
+		}
+		servers, _, err := config.NewServerConfigParser().Parse(*config.NewDefaultConfig(), *ingCfg, c.tlsCerts, c.endpoints)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mergeList = append(mergeList, collision.IngressConfig{Ingress: ing, Servers: servers})
+	}
+
+	merged, err := collision.NewMergingCollisionHandler().Resolve(mergeList)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	for i := range merged {
+		sc, err := r.RenderServerConfig(&merged[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		buffer.Write(sc.Config)
+	}
+	return buffer.Bytes()
+}
+
+func TestRenderServerConfigGolden(t *testing.T) {
+	r := NewRenderer()
+	for _, c := range goldenCases {
+		t.Run(c.name, func(t *testing.T) {
+			golden := filepath.Join("testdata", c.name+".golden")
+			rendered := renderGolden(t, r, c)
+			if *update {
+				if err := os.MkdirAll("testdata", 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(golden, rendered, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v, run the tests with -update to create the golden files", err)
+			}
+			for i := 0; i < goldenRuns; i++ {
+				if !bytes.Equal(expected, rendered) {
+					t.Fatalf("rendered config does not match %s in run %d:\n%s", golden, i, rendered)
+				}
+				rendered = renderGolden(t, r, c)
+			}
+		})
+	}
+}
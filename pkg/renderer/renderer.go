@@ -1,42 +1,117 @@
 package renderer
 
+//go:generate go run gen_templates.go
+
 import (
 	"bytes"
+	"fmt"
+	"sync"
 	"text/template"
 
 	"github.com/thetechnick/nginx-ingress/pkg/collision"
 	"github.com/thetechnick/nginx-ingress/pkg/config"
 	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
 
-	log "github.com/sirupsen/logrus"
+// Template names
+const (
+	MainConfigTemplateName = "nginx.conf.tmpl"
+	IngressTemplateName    = "ingress.tmpl"
 )
 
+// TemplateError is returned if a template cannot be parsed or rendered
+type TemplateError struct {
+	Template string
+	Err      error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("Template %q is invalid: %v", e.Template, e.Err)
+}
+
 // Renderer generates storage objects
 type Renderer interface {
 	RenderMainConfig(mainConfig *MainConfigTemplateData) (*pb.MainConfig, error)
 	RenderServerConfig(mergedConfig *collision.MergedIngressConfig) (*pb.ServerConfig, error)
+	// UpdateTemplates replaces the templates used for rendering, empty templates are replaced by the
+	// embedded defaults. The templates are validated by rendering a fixture, if a *TemplateError
+	// is returned the current templates are kept.
+	UpdateTemplates(mainConfigTemplate, ingressTemplate string) error
 }
 
 type renderer struct {
+	// mutex protects the templates, which are replaced while rendering
+	mutex              sync.RWMutex
 	mainConfigTemplate *template.Template
 	serverTemplate     *template.Template
 }
 
-// NewRenderer creates a new Renderer
+// NewRenderer creates a new Renderer using the embedded default templates
 func NewRenderer() Renderer {
 	c := &renderer{}
-	serverTemplate, err := template.New("ingress.tmpl").ParseFiles("ingress.tmpl")
+	if err := c.UpdateTemplates("", ""); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (c *renderer) UpdateTemplates(mainConfigTemplate, ingressTemplate string) error {
+	if mainConfigTemplate == "" {
+		mainConfigTemplate = defaultMainConfigTemplate
+	}
+	if ingressTemplate == "" {
+		ingressTemplate = defaultIngressTemplate
+	}
+
+	mainTmpl, err := parseTemplate(MainConfigTemplateName, mainConfigTemplate)
+	if err != nil {
+		return err
+	}
+	serverTmpl, err := parseTemplate(IngressTemplateName, ingressTemplate)
+	if err != nil {
+		return err
+	}
+
+	mainFixture, serverFixture := templateFixtures()
+	if _, err = renderTemplate(mainTmpl, mainFixture); err != nil {
+		return err
+	}
+	if _, err = renderServerTemplate(serverTmpl, serverFixture); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.mainConfigTemplate = mainTmpl
+	c.serverTemplate = serverTmpl
+	return nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs(nil)).Parse(text)
 	if err != nil {
-		log.WithError(err).Fatal("Error parsing main config template")
+		return nil, &TemplateError{Template: name, Err: err}
 	}
-	c.serverTemplate = serverTemplate
+	return tmpl, nil
+}
 
-	mainConfigTemplate, err := template.New("nginx.conf.tmpl").ParseFiles("nginx.conf.tmpl")
+func renderTemplate(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return nil, &TemplateError{Template: tmpl.Name(), Err: err}
+	}
+	return buffer.Bytes(), nil
+}
+
+// renderServerTemplate renders the server of the merged config,
+// the template helpers are bound to the ingress objects of the server
+func renderServerTemplate(tmpl *template.Template, mergedConfig *collision.MergedIngressConfig) ([]byte, error) {
+	clone, err := tmpl.Clone()
 	if err != nil {
-		log.WithError(err).Fatal("Error parsing server template")
+		return nil, &TemplateError{Template: tmpl.Name(), Err: err}
 	}
-	c.mainConfigTemplate = mainConfigTemplate
-	return c
+	return renderTemplate(clone.Funcs(templateFuncs(mergedConfig.Ingress)), mergedConfig.Server)
 }
 
 func (c *renderer) RenderMainConfig(mainCfg *MainConfigTemplateData) (*pb.MainConfig, error) {
@@ -47,33 +122,44 @@ func (c *renderer) RenderMainConfig(mainCfg *MainConfigTemplateData) (*pb.MainCo
 		}
 	}
 
-	var buffer bytes.Buffer
-	err := c.mainConfigTemplate.Execute(&buffer, mainCfg)
+	c.mutex.RLock()
+	tmpl := c.mainConfigTemplate
+	c.mutex.RUnlock()
+
+	content, err := renderTemplate(tmpl, mainCfg)
 	if err != nil {
 		return nil, err
 	}
-	mc.Config = buffer.Bytes()
+	mc.Config = content
 	return mc, nil
 }
 
 func (c *renderer) RenderServerConfig(mergedConfig *collision.MergedIngressConfig) (*pb.ServerConfig, error) {
-	var buffer bytes.Buffer
-	if err := c.serverTemplate.Execute(&buffer, mergedConfig.Server); err != nil {
+	c.mutex.RLock()
+	tmpl := c.serverTemplate
+	c.mutex.RUnlock()
+
+	content, err := renderServerTemplate(tmpl, mergedConfig)
+	if err != nil {
 		return nil, err
 	}
 
+	// rejected ingress objects are recorded as well, so they are updated
+	// and take over the host when the ingress objects owning it are gone
 	meta := map[string]string{}
-	for _, ing := range mergedConfig.Ingress {
-		ingKey, err := config.KeyFunc(ing)
-		if err != nil {
-			return nil, err
+	for _, ingresses := range [][]*v1beta1.Ingress{mergedConfig.Ingress, mergedConfig.Rejected} {
+		for _, ing := range ingresses {
+			ingKey, err := config.KeyFunc(ing)
+			if err != nil {
+				return nil, err
+			}
+			meta[ingKey] = ""
 		}
-		meta[ingKey] = ""
 	}
 	s := &pb.ServerConfig{
 		Meta:   meta,
 		Name:   mergedConfig.Server.Name,
-		Config: buffer.Bytes(),
+		Config: content,
 		Files:  mergedConfig.Server.Files,
 	}
 	return s, nil
@@ -71,8 +71,27 @@ func TestRenderer(t *testing.T) {
 			assert.Regexp("ssl_certificate cert.pem;", config)
 			assert.Regexp("ssl_certificate_key key.pem;", config)
 			assert.Regexp("server_name one.example.com;", config)
-			assert.Regexp("auth_basic test;", config)
+			assert.Regexp(`auth_basic "test";`, config)
 			assert.Regexp("auth_basic_user_file test.auth;", config)
 		}
 	})
+
+	t.Run("RenderServerConfig records rejected ingresses in the metadata", func(t *testing.T) {
+		c := NewRenderer()
+		assert := assert.New(t)
+
+		mc := &collision.MergedIngressConfig{
+			Ingress: []*v1beta1.Ingress{
+				&v1beta1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ing1", Namespace: "default"}},
+			},
+			Rejected: []*v1beta1.Ingress{
+				&v1beta1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ing2", Namespace: "default"}},
+			},
+			Server: &config.Server{Name: "one.example.com"},
+		}
+		sc, err := c.RenderServerConfig(mc)
+		if assert.NoError(err) {
+			assert.Equal(map[string]string{"default/ing1": "", "default/ing2": ""}, sc.Meta)
+		}
+	})
 }
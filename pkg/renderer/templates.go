@@ -0,0 +1,9 @@
+// Code generated by gen_templates.go. DO NOT EDIT.
+
+package renderer
+
+// defaultMainConfigTemplate is the embedded nginx.conf.tmpl
+const defaultMainConfigTemplate = "\nuser  nginx;\nworker_processes  {{.WorkerProcesses}};\n{{- if .WorkerRLimitNofile}}\nworker_rlimit_nofile {{.WorkerRLimitNofile}};\n{{- end}}\n{{- if .WorkerCPUAffinity}}\nworker_cpu_affinity {{.WorkerCPUAffinity}};\n{{- end}}\n{{- if .WorkerShutdownTimeout}}\nworker_shutdown_timeout {{.WorkerShutdownTimeout}};\n{{- end}}\n\nerror_log  /var/log/nginx/error.log {{.ErrorLogLevel}};\npid        /var/run/nginx.pid;\n\n\nevents {\n    worker_connections  {{.WorkerConnections}};\n    multi_accept on;\n    use epoll;\n}\n\n\nhttp {\n    server_tokens off;\n    include       /etc/nginx/mime.types;\n    default_type  application/octet-stream;\n\n    {{- if .HTTPSnippets}}\n    {{range $value := .HTTPSnippets}}\n    {{$value}}{{end}}\n    {{- end}}\n\n    {{if .LogFormat -}}\n    log_format  main  '{{escape .LogFormat}}';\n    {{- else -}}\n    log_format  main  '$remote_addr - $remote_user [$time_local] \"$request\" '\n                      '$status $body_bytes_sent \"$http_referer\" '\n                      '\"$http_user_agent\" \"$http_x_forwarded_for\"';\n    {{- end }}\n    {{if .AccessLogOff -}}\n    access_log  off;\n    {{- else -}}\n    access_log  {{.AccessLogPath}}  main;\n    {{- end}}\n\n    sendfile        on;\n    #tcp_nopush     on;\n\n    keepalive_timeout  {{.KeepaliveTimeout}};\n    {{- if .KeepaliveRequests}}\n    keepalive_requests {{.KeepaliveRequests}};\n    {{- end}}\n\n    gzip  {{if .Gzip}}on{{else}}off{{end}};\n    {{- if .Gzip}}\n    gzip_comp_level {{.GzipLevel}};\n    gzip_min_length {{.GzipMinLength}};\n    {{- if .GzipTypes}}\n    gzip_types {{join \" \" .GzipTypes}};\n    {{- end}}\n    {{- end}}\n    {{- if .Brotli}}\n    brotli on;\n    brotli_min_length {{.GzipMinLength}};\n    {{- if .GzipTypes}}\n    brotli_types {{join \" \" .GzipTypes}};\n    {{- end}}\n    {{- end}}\n\n    server_names_hash_max_size {{.ServerNamesHashMaxSize}};\n    {{if .ServerNamesHashBucketSize}}server_names_hash_bucket_size {{.ServerNamesHashBucketSize}};{{end}}\n\n    map $http_upgrade $connection_upgrade {\n        default upgrade;\n        ''      close;\n    }\n    {{if .SSLProtocols}}ssl_protocols {{.SSLProtocols}};{{end}}\n    {{if .SSLCiphers}}ssl_ciphers {{quote .SSLCiphers}};{{end}}\n    {{if .SSLPreferServerCiphers}}ssl_prefer_server_ciphers on;{{end}}\n    {{if .SSLDHParamsFile }}ssl_dhparam {{.SSLDHParamsFile.Name}};{{end}}\n\n    {{if .HealthStatus}}\n    server {\n        listen 80 default_server;\n        server_name _;\n\n        location /nginx-health {\n            access_log off;\n            default_type text/plain;\n            return 200 \"healthy\\n\";\n        }\n    }\n    {{end}}\n\n    include /etc/nginx/conf.d/*.conf;\n}\n"
+
+// defaultIngressTemplate is the embedded ingress.tmpl
+const defaultIngressTemplate = "{{range $upstream := .Upstreams}}\nupstream {{$upstream.Name}} {\n\t{{range $server := $upstream.UpstreamServers}}\n\tserver {{$server.Address}}:{{$server.Port}};{{end}}\n}{{end}}\n\nserver {\n\tlisten 80{{if .H2C}} http2{{end}}{{if .ProxyProtocol}} proxy_protocol{{end}}{{if not .Name}} default_server{{end}};\n\t{{if .SSL}}\n\tlisten 443 ssl{{if .HTTP2}} http2{{end}}{{if .ProxyProtocol}} proxy_protocol{{end}}{{if not .Name}} default_server{{end}};\n\tssl_certificate {{.SSLCertificate}};\n\tssl_certificate_key {{.SSLCertificateKey}};\n\t{{end}}\n\t{{range $setRealIPFrom := .SetRealIPFrom}}\n\tset_real_ip_from {{$setRealIPFrom}};{{end}}\n\t{{if .RealIPHeader}}real_ip_header {{.RealIPHeader}};{{end}}\n\t{{if .RealIPRecursive}}real_ip_recursive on;{{end}}\n\n\t{{if not .ServerTokens}}server_tokens off;{{end}}\n\n\tgzip {{if .Gzip}}on{{else}}off{{end}};\n\t{{- if .Gzip}}\n\tgzip_comp_level {{.GzipLevel}};\n\tgzip_min_length {{.GzipMinLength}};\n\t{{- if .GzipTypes}}\n\tgzip_types {{join \" \" .GzipTypes}};\n\t{{- end}}\n\t{{- end}}\n\t{{- if .BrotliModule}}\n\tbrotli {{if .Brotli}}on{{else}}off{{end}};\n\t{{- if .Brotli}}\n\tbrotli_min_length {{.GzipMinLength}};\n\t{{- if .GzipTypes}}\n\tbrotli_types {{join \" \" .GzipTypes}};\n\t{{- end}}\n\t{{- end}}\n\t{{- end}}\n\n\t{{if .Name}}\n\tserver_name {{.Name}};\n\t{{end}}\n\t{{range $proxyHideHeader := .ProxyHideHeaders}}\n\tproxy_hide_header {{$proxyHideHeader}};{{end}}\n\t{{range $proxyPassHeader := .ProxyPassHeaders}}\n\tproxy_pass_header {{$proxyPassHeader}};{{end}}\n\t{{if .SSL}}\n\tif ($scheme = http) {\n\t\treturn 301 https://$host$request_uri;\n\t}\n\t{{- if .HSTS}}\n\tproxy_hide_header Strict-Transport-Security;\n\tadd_header Strict-Transport-Security \"max-age={{.HSTSMaxAge}}; {{if .HSTSIncludeSubdomains}}includeSubDomains; {{end}}preload\" always;{{end}}\n\t{{- end}}\n\t{{- if .RedirectToHTTPS}}\n\tif ($http_x_forwarded_proto = 'http') {\n\t\treturn 301 https://$host$request_uri;\n\t}\n\t{{- end}}\n\n\t{{- if .ServerSnippets}}\n\t{{range $value := .ServerSnippets}}\n\t{{$value}}{{end}}\n\t{{- end}}\n\n\t{{range $location := .Locations}}\n\tlocation {{$location.Path}} {\n\t\t{{if $location.HTTP}}proxy_http_version 1.1;{{end}}\n\t\t{{if $location.Websocket}}\n\t\tproxy_set_header Upgrade $http_upgrade;\n\t\tproxy_set_header Connection $connection_upgrade;\n\t\t{{end}}\n\n\t\t{{- if $location.BasicAuth}}\n\t\tauth_basic {{quote $location.BasicAuth}};\n\t\tauth_basic_user_file {{$location.BasicAuthUserFile}};\n\t\t{{- end}}\n\n\t\t{{- if $location.LocationSnippets}}\n\t\t{{range $value := $location.LocationSnippets}}\n\t\t{{$value}}{{end}}\n\t\t{{- end}}\n\n\t\t{{if $location.GRPC -}}\n\t\tgrpc_connect_timeout {{$location.ProxyConnectTimeout}};\n\t\tgrpc_read_timeout {{$location.ProxyReadTimeout}};\n\t\tclient_max_body_size {{$location.ClientMaxBodySize}};\n\t\tgrpc_set_header Host $host;\n\t\tgrpc_set_header X-Real-IP $remote_addr;\n\t\tgrpc_set_header X-Forwarded-For $proxy_add_x_forwarded_for;\n\t\tgrpc_set_header X-Forwarded-Host $host;\n\t\tgrpc_set_header X-Forwarded-Port $server_port;\n\t\tgrpc_set_header X-Forwarded-Proto {{if $.RedirectToHTTPS}}https{{else}}$scheme{{end}};\n\t\t{{- if $location.SSL}}\n\t\t{{- if $location.ProxySSLTrustedCertificate}}\n\t\tgrpc_ssl_trusted_certificate {{$location.ProxySSLTrustedCertificate}};\n\t\tgrpc_ssl_verify {{if $location.ProxySSLVerify}}on{{else}}off{{end}};\n\t\t{{- end}}\n\t\t{{- if $location.ProxySSLCertificate}}\n\t\tgrpc_ssl_certificate {{$location.ProxySSLCertificate}};\n\t\tgrpc_ssl_certificate_key {{$location.ProxySSLCertificate}};\n\t\t{{- end}}\n\t\t{{- if $location.ProxySSLName}}\n\t\tgrpc_ssl_name {{$location.ProxySSLName}};\n\t\tgrpc_ssl_server_name on;\n\t\t{{- end}}\n\t\t{{- end}}\n\t\tgrpc_pass grpc{{if $location.SSL}}s{{end}}://{{$location.Upstream.Name}};\n\t\t{{- else if $location.FastCGI -}}\n\t\tfastcgi_connect_timeout {{$location.ProxyConnectTimeout}};\n\t\tfastcgi_read_timeout {{$location.ProxyReadTimeout}};\n\t\tclient_max_body_size {{$location.ClientMaxBodySize}};\n\t\tinclude fastcgi_params;\n\t\t{{- if $location.FastCGIIndex}}\n\t\tfastcgi_index {{$location.FastCGIIndex}};\n\t\t{{- end}}\n\t\t{{- range $name, $value := $location.FastCGIParams}}\n\t\tfastcgi_param {{$name}} {{quote $value}};\n\t\t{{- end}}\n\t\tfastcgi_pass {{$location.Upstream.Name}};\n\t\t{{- else if $location.UWSGI -}}\n\t\tuwsgi_connect_timeout {{$location.ProxyConnectTimeout}};\n\t\tuwsgi_read_timeout {{$location.ProxyReadTimeout}};\n\t\tclient_max_body_size {{$location.ClientMaxBodySize}};\n\t\tinclude uwsgi_params;\n\t\tuwsgi_pass {{$location.Upstream.Name}};\n\t\t{{- else -}}\n\t\tproxy_connect_timeout {{$location.ProxyConnectTimeout}};\n\t\tproxy_read_timeout {{$location.ProxyReadTimeout}};\n\t\tclient_max_body_size {{$location.ClientMaxBodySize}};\n\t\tproxy_set_header Host $host;\n\t\tproxy_set_header X-Real-IP $remote_addr;\n\t\tproxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;\n\t\tproxy_set_header X-Forwarded-Host $host;\n\t\tproxy_set_header X-Forwarded-Port $server_port;\n\t\tproxy_set_header X-Forwarded-Proto {{if $.RedirectToHTTPS}}https{{else}}$scheme{{end}};\n\n\t\tproxy_buffering {{if $location.ProxyBuffering}}on{{else}}off{{end}};\n\t\t{{- if $location.ProxyBuffers}}\n\t\tproxy_buffers {{$location.ProxyBuffers}};\n\t\t{{- end}}\n\t\t{{- if $location.ProxyBufferSize}}\n\t\tproxy_buffer_size {{$location.ProxyBufferSize}};\n\t\t{{- end}}\n\t\t{{- if $location.ProxyMaxTempFileSize}}\n\t\tproxy_max_temp_file_size {{$location.ProxyMaxTempFileSize}};\n\t\t{{- end}}\n\t\t{{- if $location.SSL}}\n\t\t{{- if $location.ProxySSLTrustedCertificate}}\n\t\tproxy_ssl_trusted_certificate {{$location.ProxySSLTrustedCertificate}};\n\t\tproxy_ssl_verify {{if $location.ProxySSLVerify}}on{{else}}off{{end}};\n\t\t{{- end}}\n\t\t{{- if $location.ProxySSLCertificate}}\n\t\tproxy_ssl_certificate {{$location.ProxySSLCertificate}};\n\t\tproxy_ssl_certificate_key {{$location.ProxySSLCertificate}};\n\t\t{{- end}}\n\t\t{{- if $location.ProxySSLName}}\n\t\tproxy_ssl_name {{$location.ProxySSLName}};\n\t\tproxy_ssl_server_name on;\n\t\t{{- end}}\n\t\t{{- end}}\n\t\t{{if $location.SSL}}\n\t\tproxy_pass https://{{$location.Upstream.Name}}{{$location.Rewrite}};\n\t\t{{else}}\n\t\tproxy_pass http://{{$location.Upstream.Name}}{{$location.Rewrite}};\n\t\t{{end}}\n\t\t{{- end}}\n\t}{{end}}\n}\n"
@@ -0,0 +1,72 @@
+package renderer
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTemplates(t *testing.T) {
+	t.Run("are up to date", func(t *testing.T) {
+		assert := assert.New(t)
+		for name, embedded := range map[string]string{
+			MainConfigTemplateName: defaultMainConfigTemplate,
+			IngressTemplateName:    defaultIngressTemplate,
+		} {
+			content, err := ioutil.ReadFile(name)
+			if !assert.NoError(err) {
+				continue
+			}
+			assert.Equal(string(content), embedded, "%s changed, run go generate", name)
+		}
+	})
+}
+
+func TestUpdateTemplates(t *testing.T) {
+	mainData, _ := templateFixtures()
+
+	t.Run("uses the given templates", func(t *testing.T) {
+		assert := assert.New(t)
+		r := NewRenderer()
+		assert.NoError(r.UpdateTemplates("# main {{.ServerNamesHashMaxSize}}", "# server {{.Name}}"))
+
+		mc, err := r.RenderMainConfig(mainData)
+		if assert.NoError(err) {
+			assert.Equal("# main 512", string(mc.Config))
+		}
+	})
+
+	t.Run("uses the defaults for empty templates", func(t *testing.T) {
+		assert := assert.New(t)
+		r := NewRenderer()
+		assert.NoError(r.UpdateTemplates("# main", "# server"))
+		assert.NoError(r.UpdateTemplates("", ""))
+
+		mc, err := r.RenderMainConfig(mainData)
+		if assert.NoError(err) {
+			assert.Contains(string(mc.Config), "include /etc/nginx/conf.d/*.conf;")
+		}
+	})
+
+	t.Run("keeps the templates if the new ones are invalid", func(t *testing.T) {
+		assert := assert.New(t)
+		r := NewRenderer()
+		assert.NoError(r.UpdateTemplates("# main", "# server"))
+
+		err := r.UpdateTemplates("# main", "{{.Name")
+		if assert.IsType(&TemplateError{}, err) {
+			assert.Equal(IngressTemplateName, err.(*TemplateError).Template)
+		}
+		// fails rendering the fixture
+		err = r.UpdateTemplates("{{.Unknown}}", "# server")
+		if assert.IsType(&TemplateError{}, err) {
+			assert.Equal(MainConfigTemplateName, err.(*TemplateError).Template)
+		}
+
+		mc, err := r.RenderMainConfig(mainData)
+		if assert.NoError(err) {
+			assert.Equal("# main", string(mc.Config))
+		}
+	})
+}
@@ -21,7 +21,26 @@ type MainConfigTemplateData struct {
 	SSLCiphers             string
 	SSLDHParamsFile        *pb.File
 
+	// http://nginx.org/en/docs/ngx_core_module.html
+	WorkerProcesses       string
+	WorkerConnections     string
+	WorkerRLimitNofile    string
+	WorkerCPUAffinity     string
 	WorkerShutdownTimeout string
+	ErrorLogLevel         string
+
+	KeepaliveTimeout  string
+	KeepaliveRequests string
+	AccessLogPath     string
+	AccessLogOff      bool
+
+	// http://nginx.org/en/docs/http/ngx_http_gzip_module.html,
+	// brotli requires the ngx_brotli module
+	Gzip          bool
+	GzipLevel     int64
+	GzipMinLength string
+	GzipTypes     []string
+	Brotli        bool
 }
 
 // MainConfigTemplateDataFromIngressConfig creates a MainConfigTemplateData from config.GlobalConfig
@@ -35,6 +54,20 @@ func MainConfigTemplateDataFromIngressConfig(config *config.GlobalConfig) *MainC
 		SSLCiphers:                config.MainServerSSLCiphers,
 		SSLPreferServerCiphers:    config.MainServerSSLPreferServerCiphers,
 		WorkerShutdownTimeout:     config.MainWorkerShutdownTimeout,
+		WorkerProcesses:           config.MainWorkerProcesses,
+		WorkerConnections:         config.MainWorkerConnections,
+		WorkerRLimitNofile:        config.MainWorkerRLimitNofile,
+		WorkerCPUAffinity:         config.MainWorkerCPUAffinity,
+		ErrorLogLevel:             config.MainErrorLogLevel,
+		KeepaliveTimeout:          config.MainKeepaliveTimeout,
+		KeepaliveRequests:         config.MainKeepaliveRequests,
+		AccessLogPath:             config.MainAccessLogPath,
+		AccessLogOff:              config.MainAccessLogOff,
+		Gzip:                      config.UseGzip,
+		GzipLevel:                 config.GzipLevel,
+		GzipMinLength:             config.GzipMinLength,
+		GzipTypes:                 config.GzipTypes,
+		Brotli:                    config.UseBrotli,
 	}
 
 	if config.MainServerSSLDHParamFile != "" {
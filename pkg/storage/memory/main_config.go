@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/thetechnick/nginx-ingress/pkg/storage"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+)
+
+// NewMainConfigStorage stores the config in memory, without configuring nginx
+func NewMainConfigStorage() storage.MainConfigStorage {
+	return &memoryMainConfigStorage{}
+}
+
+type memoryMainConfigStorage struct {
+	mutex sync.Mutex
+	store *pb.MainConfig
+}
+
+func (s *memoryMainConfigStorage) Get() (*pb.MainConfig, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.store, nil
+}
+
+func (s *memoryMainConfigStorage) Put(cfg *pb.MainConfig) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.store = cfg
+	return nil
+}
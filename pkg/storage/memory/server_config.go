@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/thetechnick/nginx-ingress/pkg/storage"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+)
+
+// NewServerConfigStorage stores the configs in memory, without configuring nginx
+func NewServerConfigStorage() storage.ServerConfigStorage {
+	return &memoryServerStorage{
+		store: map[string]*pb.ServerConfig{},
+	}
+}
+
+type memoryServerStorage struct {
+	mutex sync.Mutex
+	store map[string]*pb.ServerConfig
+}
+
+func (s *memoryServerStorage) Put(cfg *pb.ServerConfig) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.store[cfg.Name] = cfg
+	return nil
+}
+
+func (s *memoryServerStorage) Delete(cfg *pb.ServerConfig) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.store, cfg.Name)
+	return nil
+}
+
+func (s *memoryServerStorage) Get(name string) (*pb.ServerConfig, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.store[name], nil
+}
+
+// List returns the configs sorted by name
+func (s *memoryServerStorage) List() ([]*pb.ServerConfig, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	cfgs := []*pb.ServerConfig{}
+	for _, cfg := range s.store {
+		cfgs = append(cfgs, cfg)
+	}
+	sort.Slice(cfgs, func(i, j int) bool {
+		return cfgs[i].Name < cfgs[j].Name
+	})
+	return cfgs, nil
+}
+
+func (s *memoryServerStorage) ByIngressKey(ingressKey string) ([]*pb.ServerConfig, error) {
+	servers, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []*pb.ServerConfig{}
+	for _, serverConfig := range servers {
+		if _, ok := serverConfig.Meta[ingressKey]; ok {
+			matching = append(matching, serverConfig)
+		}
+	}
+	return matching, nil
+}
@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+)
+
+func TestMemoryServerStorage(t *testing.T) {
+	assert := assert.New(t)
+	s := NewServerConfigStorage()
+
+	b := &pb.ServerConfig{Name: "b", Meta: map[string]string{"default/ing1": ""}}
+	a := &pb.ServerConfig{Name: "a", Meta: map[string]string{"default/ing1": "", "default/ing2": ""}}
+	assert.NoError(s.Put(b))
+	assert.NoError(s.Put(a))
+
+	cfg, err := s.Get("a")
+	assert.NoError(err)
+	assert.Equal(a, cfg)
+
+	cfgs, err := s.List()
+	assert.NoError(err)
+	assert.Equal([]*pb.ServerConfig{a, b}, cfgs)
+
+	cfgs, err = s.ByIngressKey("default/ing2")
+	assert.NoError(err)
+	assert.Equal([]*pb.ServerConfig{a}, cfgs)
+
+	assert.NoError(s.Delete(a))
+	cfg, err = s.Get("a")
+	assert.NoError(err)
+	assert.Nil(cfg)
+}
@@ -13,6 +13,8 @@ const (
 	AuthDir = "/etc/nginx/auth/"
 	// DHParamFile path to the DHParamsFile
 	DHParamFile = "/etc/nginx/ssl/dhparam.pem"
+	// LogDir contains the log files
+	LogDir = "/var/log/nginx/"
 )
 
 // ServerConfigStorage stores ServerConfigs
@@ -0,0 +1,192 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// http://nginx.org/en/docs/syntax.html
+var (
+	numberRegexp     = regexp.MustCompile(`^[1-9]\d*$`)
+	cpuMaskRegexp    = regexp.MustCompile(`^[01]+$`)
+	sizeRegexp       = regexp.MustCompile(`^\d+[kKmM]?$`)
+	offsetRegexp     = regexp.MustCompile(`^\d+[kKmMgG]?$`)
+	timeRegexp       = regexp.MustCompile(`^(\d+(ms|s|m|h|d|w|M|y) ?)*(\d+(ms|s|m|h|d|w|M|y)|\d+)$`)
+	bufferSpecRegexp = regexp.MustCompile(`^\d+ +\d+[kKmM]?$`)
+	// https://tools.ietf.org/html/rfc7230#section-3.2.6, without the quote character
+	headerNameRegexp = regexp.MustCompile("^[A-Za-z0-9!#$%&*+.^_`|~-]+$")
+	// https://tools.ietf.org/html/rfc6838#section-4.2, with "*" as subtype wildcard
+	mimeTypeRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*/([A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*|\*)$`)
+	fileNameRegexp = regexp.MustCompile(`^[A-Za-z0-9._~@+-]+$`)
+	// https://tools.ietf.org/html/rfc1123#section-2.1
+	serverNameRegexp = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+)
+
+// http://nginx.org/en/docs/ngx_core_module.html#error_log
+var logLevels = map[string]bool{
+	"debug":  true,
+	"info":   true,
+	"notice": true,
+	"warn":   true,
+	"error":  true,
+	"crit":   true,
+	"alert":  true,
+	"emerg":  true,
+}
+
+// ParseNumber validates a positive number like "1024"
+func ParseNumber(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !numberRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid number %q, expected a positive number", s)
+	}
+	return s, nil
+}
+
+// ParseWorkerProcesses validates the number of worker processes, a positive number or "auto"
+func ParseWorkerProcesses(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "auto" {
+		return s, nil
+	}
+	if _, err := ParseNumber(s); err != nil {
+		return "", fmt.Errorf("invalid worker processes %q, expected a positive number or \"auto\"", s)
+	}
+	return s, nil
+}
+
+// ParseCPUAffinity validates the CPU affinity of the worker processes,
+// one or more CPU masks like "0101 1010", "auto" or "auto" followed by a mask limiting the CPUs
+func ParseCPUAffinity(s string) (string, error) {
+	fields := strings.Fields(s)
+	masks := fields
+	if len(fields) > 0 && fields[0] == "auto" {
+		masks = fields[1:]
+		if len(masks) > 1 {
+			return "", fmt.Errorf("invalid CPU affinity %q, expected at most one CPU mask after \"auto\"", s)
+		}
+	} else if len(fields) == 0 {
+		return "", fmt.Errorf("invalid CPU affinity %q, expected CPU masks like \"0101 1010\" or \"auto\"", s)
+	}
+	for _, mask := range masks {
+		if !cpuMaskRegexp.MatchString(mask) {
+			return "", fmt.Errorf("invalid CPU mask %q, expected a bitmask of 0 and 1", mask)
+		}
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// ParseLogLevel validates an error log level like "warn" or "error"
+func ParseLogLevel(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !logLevels[s] {
+		return "", fmt.Errorf("invalid log level %q, expected one of debug, info, notice, warn, error, crit, alert or emerg", s)
+	}
+	return s, nil
+}
+
+// ParseSize validates a nginx size value like "512", "8k" or "1m"
+func ParseSize(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !sizeRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid size %q, expected a number optionally followed by k or m", s)
+	}
+	return s, nil
+}
+
+// ParseOffset validates a nginx offset value like "512", "8k", "1m" or "1g"
+func ParseOffset(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !offsetRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid offset %q, expected a number optionally followed by k, m or g", s)
+	}
+	return s, nil
+}
+
+// ParseTime validates a nginx time value like "60", "10s", "500ms" or "1h30m".
+// Values with multiple units like "1h 30m" are concatenated to "1h30m",
+// as directives would take the separated units as additional arguments.
+func ParseTime(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !timeRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid time %q, expected numbers followed by one of ms, s, m, h, d, w, M, y", s)
+	}
+	return strings.Replace(s, " ", "", -1), nil
+}
+
+// ParseBufferSpec validates a nginx buffer spec in the format "<number> <size>" like "8 4k"
+func ParseBufferSpec(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !bufferSpecRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid buffer spec %q, expected the format \"<number> <size>\"", s)
+	}
+	return strings.Join(strings.Fields(s), " "), nil
+}
+
+// ParseHeaderName validates a HTTP header name like "X-Forwarded-For"
+func ParseHeaderName(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !headerNameRegexp.MatchString(s) || strings.HasPrefix(s, "#") {
+		return "", fmt.Errorf("invalid header name %q", s)
+	}
+	return s, nil
+}
+
+// ParseServerName validates a DNS name like "backend.example.com"
+func ParseServerName(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 253 || !serverNameRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid server name %q, expected a DNS name", s)
+	}
+	return s, nil
+}
+
+// ParseMIMEType validates a MIME type like "application/json", "text/*" or "*" for all types
+func ParseMIMEType(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s != "*" && !mimeTypeRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid MIME type %q, expected <type>/<subtype>", s)
+	}
+	return s, nil
+}
+
+// ParseFileName validates a file name without directory like "index.php"
+func ParseFileName(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !fileNameRegexp.MatchString(s) || s == "." || s == ".." {
+		return "", fmt.Errorf("invalid file name %q", s)
+	}
+	return s, nil
+}
+
+// ParsePath validates a URI path used in proxy_pass directives,
+// it must start with "/" and must be a valid location path
+func ParsePath(s string) (string, error) {
+	if !strings.HasPrefix(s, "/") {
+		return "", fmt.Errorf("invalid path %q, expected an absolute path", s)
+	}
+	return ParseLocationPath(s)
+}
+
+// ParseLocationPath validates a prefix or regular expression used in location directives,
+// it must not contain whitespace, quotes, ";", "{" or "}" and must not end with a backslash
+func ParseLocationPath(s string) (string, error) {
+	if s == "" || strings.ContainsAny(s, " \t\r\n;{}\"'") || strings.HasSuffix(s, "\\") {
+		return "", fmt.Errorf("invalid path %q, must not contain whitespace, quotes, \";\", \"{\" or \"}\"", s)
+	}
+	return s, nil
+}
+
+// ParseAddress validates an IP address, a CIDR like "10.0.0.0/8" or "unix:"
+func ParseAddress(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "unix:" || net.ParseIP(s) != nil {
+		return s, nil
+	}
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid address %q, expected an IP address, a CIDR or \"unix:\"", s)
+}
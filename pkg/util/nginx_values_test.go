@@ -0,0 +1,274 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	valid := map[string]string{
+		"512":  "512",
+		"8k":   "8k",
+		"16K":  "16K",
+		"1m":   "1m",
+		" 4k ": "4k",
+	}
+	for input, expected := range valid {
+		v, err := ParseSize(input)
+		if err != nil {
+			t.Errorf("ParseSize(%q) returned unexpected error: %v", input, err)
+		}
+		if v != expected {
+			t.Errorf("ParseSize(%q) returned %q, expected %q", input, v, expected)
+		}
+	}
+
+	invalid := []string{"", "k", "1g", "-1", "1.5m", "8k;", "8 k"}
+	for _, input := range invalid {
+		if _, err := ParseSize(input); err == nil {
+			t.Errorf("ParseSize(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	valid := []string{"0", "1024", "1m", "1g", "2G"}
+	for _, input := range valid {
+		if _, err := ParseOffset(input); err != nil {
+			t.Errorf("ParseOffset(%q) returned unexpected error: %v", input, err)
+		}
+	}
+
+	invalid := []string{"", "1t", "1gb", "m1", "1m\nreturn 200"}
+	for _, input := range invalid {
+		if _, err := ParseOffset(input); err == nil {
+			t.Errorf("ParseOffset(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	valid := map[string]string{
+		"60":        "60",
+		"10s":       "10s",
+		"500ms":     "500ms",
+		"1h30m":     "1h30m",
+		"1h 30m":    "1h30m",
+		" 1d 2h 3m": "1d2h3m",
+		"2w":        "2w",
+		"1M":        "1M",
+		"1y":        "1y",
+	}
+	for input, expected := range valid {
+		v, err := ParseTime(input)
+		if err != nil {
+			t.Errorf("ParseTime(%q) returned unexpected error: %v", input, err)
+		}
+		if v != expected {
+			t.Errorf("ParseTime(%q) returned %q, expected %q", input, v, expected)
+		}
+	}
+
+	invalid := []string{"", "s", "10 s", "10sec", "1.5s", "-1s", "10s;", "1h  30m"}
+	for _, input := range invalid {
+		if _, err := ParseTime(input); err == nil {
+			t.Errorf("ParseTime(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParseBufferSpec(t *testing.T) {
+	valid := map[string]string{
+		"8 4k":     "8 4k",
+		"4 16":     "4 16",
+		" 8   1m ": "8 1m",
+	}
+	for input, expected := range valid {
+		v, err := ParseBufferSpec(input)
+		if err != nil {
+			t.Errorf("ParseBufferSpec(%q) returned unexpected error: %v", input, err)
+		}
+		if v != expected {
+			t.Errorf("ParseBufferSpec(%q) returned %q, expected %q", input, v, expected)
+		}
+	}
+
+	invalid := []string{"", "8", "4k", "8 4g", "8 4k 1", "8\t4k", "k 4k"}
+	for _, input := range invalid {
+		if _, err := ParseBufferSpec(input); err == nil {
+			t.Errorf("ParseBufferSpec(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParseHeaderName(t *testing.T) {
+	valid := map[string]string{
+		"X-Forwarded-For": "X-Forwarded-For",
+		" Server ":        "Server",
+		"proxy_protocol":  "proxy_protocol",
+	}
+	for input, expected := range valid {
+		if v, err := ParseHeaderName(input); err != nil || v != expected {
+			t.Errorf("ParseHeaderName(%q) returned %q, %v, expected %q", input, v, err, expected)
+		}
+	}
+
+	invalid := []string{"", "X Foo", "X-Foo;", "#X-Foo", "'X-Foo'", "X-Foo\nreturn", "X{}"}
+	for _, input := range invalid {
+		if _, err := ParseHeaderName(input); err == nil {
+			t.Errorf("ParseHeaderName(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	valid := []string{"/", "/coffee/beans", "/$1", "/a#b"}
+	for _, input := range valid {
+		if _, err := ParsePath(input); err != nil {
+			t.Errorf("ParsePath(%q) returned unexpected error: %v", input, err)
+		}
+	}
+
+	invalid := []string{"", "coffee", "/a b", "/a;", "/a{1}", "/\"a\"", "/a\\"}
+	for _, input := range invalid {
+		if _, err := ParsePath(input); err == nil {
+			t.Errorf("ParsePath(%q) should have returned an error", input)
+		}
+	}
+
+	if _, err := ParseLocationPath(`\.(css|js)$`); err != nil {
+		t.Errorf("ParseLocationPath should accept regular expressions, got: %v", err)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	valid := []string{"10.0.0.1", " 10.0.0.0/8 ", "fd00::/8", "unix:"}
+	for _, input := range valid {
+		if _, err := ParseAddress(input); err != nil {
+			t.Errorf("ParseAddress(%q) returned unexpected error: %v", input, err)
+		}
+	}
+
+	invalid := []string{"", "10.0.0", "10.0.0.0/33", "10.0.0.1;", "unix:/tmp/sock"}
+	for _, input := range invalid {
+		if _, err := ParseAddress(input); err == nil {
+			t.Errorf("ParseAddress(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParseServerName(t *testing.T) {
+	valid := []string{"backend", " backend.example.com ", "svc-1.default.svc.cluster.local"}
+	for _, input := range valid {
+		if _, err := ParseServerName(input); err != nil {
+			t.Errorf("ParseServerName(%q) returned unexpected error: %v", input, err)
+		}
+	}
+
+	invalid := []string{"", "-backend", "backend.", "back end", "backend;", "$host", "*.example.com"}
+	for _, input := range invalid {
+		if _, err := ParseServerName(input); err == nil {
+			t.Errorf("ParseServerName(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParseWorkerSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (string, error)
+		valid   []string
+		invalid []string
+	}{
+		{"ParseNumber", ParseNumber, []string{"1", " 1024 ", "65536"}, []string{"", "0", "-1", "01", "1k", "1024; daemon off"}},
+		{"ParseWorkerProcesses", ParseWorkerProcesses, []string{"auto", "1", " 64 "}, []string{"", "0", "max", "auto 4"}},
+		{"ParseCPUAffinity", ParseCPUAffinity, []string{"auto", "auto 01010101", "0101 1010", " 01  10 "}, []string{"", "auto 01 10", "0102", "auto;", "1 ;"}},
+		{"ParseLogLevel", ParseLogLevel, []string{"debug", " warn ", "emerg"}, []string{"", "WARN", "warning", "warn; daemon off"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, input := range test.valid {
+				if _, err := test.parse(input); err != nil {
+					t.Errorf("%s(%q) returned unexpected error: %v", test.name, input, err)
+				}
+			}
+			for _, input := range test.invalid {
+				if _, err := test.parse(input); err == nil {
+					t.Errorf("%s(%q) should have returned an error", test.name, input)
+				}
+			}
+		})
+	}
+
+	t.Run("ParseCPUAffinity normalizes whitespace", func(t *testing.T) {
+		if v, _ := ParseCPUAffinity(" 01 \t 10 "); v != "01 10" {
+			t.Errorf("ParseCPUAffinity returned %q, expected %q", v, "01 10")
+		}
+	})
+}
+
+func TestParseMIMEType(t *testing.T) {
+	valid := []string{"*", "text/*", " application/json ", "application/vnd.api+json", "image/svg+xml"}
+	for _, input := range valid {
+		if _, err := ParseMIMEType(input); err != nil {
+			t.Errorf("ParseMIMEType(%q) returned unexpected error: %v", input, err)
+		}
+	}
+
+	invalid := []string{"", "text", "*/*", "text/", "text/html;", "text/html charset=utf-8", "text/html{"}
+	for _, input := range invalid {
+		if _, err := ParseMIMEType(input); err == nil {
+			t.Errorf("ParseMIMEType(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParseFileName(t *testing.T) {
+	valid := []string{"index.php", " app_dev.php ", "index"}
+	for _, input := range valid {
+		if _, err := ParseFileName(input); err != nil {
+			t.Errorf("ParseFileName(%q) returned unexpected error: %v", input, err)
+		}
+	}
+
+	invalid := []string{"", "..", "/index.php", "index.php;", "index php", "$uri"}
+	for _, input := range invalid {
+		if _, err := ParseFileName(input); err == nil {
+			t.Errorf("ParseFileName(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestGetMapKeyAsTime(t *testing.T) {
+	m := map[string]string{
+		"valid":   "10s",
+		"invalid": "ten seconds",
+	}
+
+	if v, exists, err := GetMapKeyAsTime(m, "valid"); !exists || err != nil || v != "10s" {
+		t.Errorf("Unexpected result for valid key: %q, %v, %v", v, exists, err)
+	}
+	if _, exists, err := GetMapKeyAsTime(m, "invalid"); !exists || err == nil {
+		t.Errorf("Expected an error for invalid key, got: %v, %v", exists, err)
+	}
+	if _, exists, err := GetMapKeyAsTime(m, "missing"); exists || err != nil {
+		t.Errorf("Unexpected result for missing key: %v, %v", exists, err)
+	}
+}
+
+func TestParseServiceMap(t *testing.T) {
+	services := map[string]bool{"svc-a": true, "svc-b": true}
+
+	values, errs := ParseServiceMap("svc-a=300s, svc-b = 8 4k,svc-c=1s,svc-a,", services)
+	if len(values) != 2 || values["svc-a"] != "300s" || values["svc-b"] != "8 4k" {
+		t.Errorf("Unexpected values: %v", values)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got: %v", errs)
+	}
+	if errs[0].Error() != `unknown service "svc-c"` {
+		t.Errorf("Unexpected error for unknown service: %v", errs[0])
+	}
+	if errs[1].Error() != `invalid entry "svc-a", expected <service>=<value>` {
+		t.Errorf("Unexpected error for invalid entry: %v", errs[1])
+	}
+}
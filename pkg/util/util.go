@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -47,3 +48,127 @@ func GetMapKeyAsStringSlice(m map[string]string, key string, context apiObject,
 	}
 	return nil, false
 }
+
+// GetMapKeyAsNumber tries to find and parse a key in a map as positive number
+func GetMapKeyAsNumber(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseNumber)
+}
+
+// GetMapKeyAsWorkerProcesses tries to find and parse a key in a map as number of worker processes
+func GetMapKeyAsWorkerProcesses(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseWorkerProcesses)
+}
+
+// GetMapKeyAsCPUAffinity tries to find and parse a key in a map as CPU affinity
+func GetMapKeyAsCPUAffinity(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseCPUAffinity)
+}
+
+// GetMapKeyAsLogLevel tries to find and parse a key in a map as error log level
+func GetMapKeyAsLogLevel(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseLogLevel)
+}
+
+// GetMapKeyAsSize tries to find and parse a key in a map as nginx size
+func GetMapKeyAsSize(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseSize)
+}
+
+// GetMapKeyAsOffset tries to find and parse a key in a map as nginx offset
+func GetMapKeyAsOffset(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseOffset)
+}
+
+// GetMapKeyAsTime tries to find and parse a key in a map as nginx time
+func GetMapKeyAsTime(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseTime)
+}
+
+// GetMapKeyAsBufferSpec tries to find and parse a key in a map as nginx buffer spec
+func GetMapKeyAsBufferSpec(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseBufferSpec)
+}
+
+// GetMapKeyAsServerName tries to find and parse a key in a map as DNS name
+func GetMapKeyAsServerName(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseServerName)
+}
+
+// GetMapKeyAsFileName tries to find and parse a key in a map as file name
+func GetMapKeyAsFileName(m map[string]string, key string) (string, bool, error) {
+	return getMapKeyWithParser(m, key, ParseFileName)
+}
+
+// GetMapKeyAsHeaderNames tries to find and parse a key in a map as comma separated list of header names
+func GetMapKeyAsHeaderNames(m map[string]string, key string) ([]string, bool, error) {
+	return getMapKeyAsListWithParser(m, key, ParseHeaderName)
+}
+
+// GetMapKeyAsAddresses tries to find and parse a key in a map as comma separated list of addresses or CIDRs
+func GetMapKeyAsAddresses(m map[string]string, key string) ([]string, bool, error) {
+	return getMapKeyAsListWithParser(m, key, ParseAddress)
+}
+
+// GetMapKeyAsMIMETypes tries to find and parse a key in a map as comma separated list of MIME types
+func GetMapKeyAsMIMETypes(m map[string]string, key string) ([]string, bool, error) {
+	return getMapKeyAsListWithParser(m, key, ParseMIMEType)
+}
+
+// ParseList parses every entry of the list, the first invalid entry fails the whole list
+func ParseList(values []string, parse func(string) (string, error)) ([]string, error) {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		v, err := parse(value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func getMapKeyAsListWithParser(m map[string]string, key string, parse func(string) (string, error)) ([]string, bool, error) {
+	if str, exists := m[key]; exists {
+		v, err := ParseList(strings.Split(str, ","), parse)
+		if err != nil {
+			return nil, exists, err
+		}
+		return v, exists, nil
+	}
+	return nil, false, nil
+}
+
+// ParseServiceMap parses a comma separated list of "<service>=<value>" pairs like "svc-a=300s,svc-b=5s".
+// Malformed entries and services that are not in services are skipped and returned as errors.
+func ParseServiceMap(s string, services map[string]bool) (map[string]string, []error) {
+	values := map[string]string{}
+	errs := []error{}
+	for _, entry := range strings.Split(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		service := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || service == "" {
+			errs = append(errs, fmt.Errorf("invalid entry %q, expected <service>=<value>", entry))
+			continue
+		}
+		if !services[service] {
+			errs = append(errs, fmt.Errorf("unknown service %q", service))
+			continue
+		}
+		values[service] = strings.TrimSpace(parts[1])
+	}
+	return values, errs
+}
+
+func getMapKeyWithParser(m map[string]string, key string, parse func(string) (string, error)) (string, bool, error) {
+	if str, exists := m[key]; exists {
+		v, err := parse(str)
+		if err != nil {
+			return "", exists, err
+		}
+		return v, exists, nil
+	}
+	return "", false, nil
+}
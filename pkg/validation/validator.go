@@ -0,0 +1,158 @@
+package validation
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetechnick/nginx-ingress/pkg/shell"
+	"github.com/thetechnick/nginx-ingress/pkg/storage"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+)
+
+// includedFiles are included by the rendered configs but not managed by the
+// controller, they are copied from the local nginx installation into the sandbox.
+// The content is used as a fallback if the file does not exist locally.
+var includedFiles = map[string]string{
+	"mime.types":     "types {}\n",
+	"fastcgi_params": "",
+	"uwsgi_params":   "",
+}
+
+// ConfigError is returned when nginx rejects a config
+type ConfigError struct {
+	ServerName string
+	Output     string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("Config for server %q is invalid: %s", e.ServerName, e.Output)
+}
+
+// Validator tests rendered configs before they are published
+type Validator interface {
+	ValidateServerConfig(mainConfig *pb.MainConfig, serverConfig *pb.ServerConfig) error
+}
+
+// NewSandboxValidator creates a Validator that writes the configs into
+// a temporary directory below baseDir and runs "nginx -t" against it.
+// If baseDir is empty the default directory for temporary files is used.
+func NewSandboxValidator(e shell.Executor, baseDir string) Validator {
+	if e == nil {
+		e = shell.NewShellExecutor()
+	}
+	return &sandboxValidator{
+		log:      log.WithField("module", "SandboxValidator"),
+		executor: e,
+		baseDir:  baseDir,
+	}
+}
+
+type sandboxValidator struct {
+	log      *log.Entry
+	executor shell.Executor
+	baseDir  string
+}
+
+func (v *sandboxValidator) ValidateServerConfig(mainConfig *pb.MainConfig, serverConfig *pb.ServerConfig) error {
+	prefix, err := ioutil.TempDir(v.baseDir, "nginx-sandbox")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(prefix)
+	v.log.WithField("server", serverConfig.Name).WithField("prefix", prefix).Debug("validating server config")
+
+	s := &sandbox{prefix: prefix}
+	if err = os.MkdirAll(s.path(storage.LogDir), 0700); err != nil {
+		return err
+	}
+	for name, fallback := range includedFiles {
+		if err = s.copyIncludedFile(name, fallback); err != nil {
+			return err
+		}
+	}
+
+	mainConfigFile := path.Join(storage.MainConfigDir, "nginx.conf")
+	if err = s.writeFile(mainConfigFile, mainConfig.Config); err != nil {
+		return err
+	}
+	for _, file := range mainConfig.Files {
+		if err = s.writeFile(file.Name, file.Content); err != nil {
+			return err
+		}
+	}
+
+	name := serverConfig.Name
+	if name == "" {
+		name = "default"
+	}
+	if err = s.writeFile(path.Join(storage.ServerConfigDir, name+".conf"), serverConfig.Config); err != nil {
+		return err
+	}
+	if serverConfig.Tls != nil {
+		if err = s.writeFile(serverConfig.Tls.Name, serverConfig.Tls.Content); err != nil {
+			return err
+		}
+	}
+	for _, file := range serverConfig.Files {
+		if err = s.writeFile(file.Name, file.Content); err != nil {
+			return err
+		}
+	}
+
+	err = v.executor.Exec(fmt.Sprintf("nginx -t -q -p %s/ -c %s", prefix, s.path(mainConfigFile)))
+	if err != nil {
+		if eerr, ok := err.(shell.ExecError); ok {
+			return &ConfigError{
+				ServerName: serverConfig.Name,
+				Output:     s.restorePaths(strings.TrimSpace(string(eerr.Output))),
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// sandbox maps the absolute paths used in the configs into the prefix directory
+type sandbox struct {
+	prefix string
+}
+
+func (s *sandbox) path(p string) string {
+	return filepath.Join(s.prefix, p)
+}
+
+func (s *sandbox) rewritePaths(content string) string {
+	content = strings.Replace(content, storage.MainConfigDir, s.path(storage.MainConfigDir)+"/", -1)
+	return strings.Replace(content, storage.LogDir, s.path(storage.LogDir)+"/", -1)
+}
+
+func (s *sandbox) restorePaths(content string) string {
+	return strings.Replace(content, s.prefix, "", -1)
+}
+
+func (s *sandbox) writeFile(name string, content []byte) error {
+	p := s.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, []byte(s.rewritePaths(string(content))), 0600)
+}
+
+// copyIncludedFile copies a file of the local nginx installation into the sandbox,
+// relative includes like "include fastcgi_params;" are resolved in the prefix
+func (s *sandbox) copyIncludedFile(file, fallback string) error {
+	name := path.Join(storage.MainConfigDir, file)
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		content = []byte(fallback)
+	}
+	return s.writeFile(name, content)
+}
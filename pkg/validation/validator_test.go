@@ -0,0 +1,151 @@
+package validation
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thetechnick/nginx-ingress/pkg/shell"
+	"github.com/thetechnick/nginx-ingress/pkg/storage/pb"
+)
+
+type ExecutorMock struct {
+	mock.Mock
+}
+
+func (e *ExecutorMock) Exec(cmd string) error {
+	args := e.Called(cmd)
+	return args.Error(0)
+}
+
+type execFunc func(cmd string) error
+
+func (f execFunc) Exec(cmd string) error {
+	return f(cmd)
+}
+
+var (
+	mainConfig = &pb.MainConfig{
+		Config: []byte("http {\n    include /etc/nginx/conf.d/*.conf;\n}\n"),
+	}
+	serverConfig = &pb.ServerConfig{
+		Name:   "one.example.com",
+		Config: []byte("server {\n    ssl_certificate /etc/nginx/ssl/one.example.com.pem;\n}\n"),
+		Files: []*pb.File{
+			&pb.File{
+				Name:    "/etc/nginx/ssl/one.example.com.pem",
+				Content: []byte("cert"),
+			},
+		},
+	}
+)
+
+func TestSandboxValidator(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "validator-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	t.Run("writes the configs into the sandbox and runs nginx -t", func(t *testing.T) {
+		assert := assert.New(t)
+		e := &ExecutorMock{}
+		v := NewSandboxValidator(e, baseDir)
+
+		var prefix string
+		var files = map[string]string{}
+		e.On("Exec", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			cmd := args.String(0)
+			prefix = strings.TrimSuffix(strings.Fields(cmd)[4], "/")
+			filepath.Walk(prefix, func(p string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					content, _ := ioutil.ReadFile(p)
+					files[strings.TrimPrefix(p, prefix)] = string(content)
+				}
+				return nil
+			})
+		})
+
+		if assert.NoError(v.ValidateServerConfig(mainConfig, serverConfig)) {
+			e.AssertCalled(t, "Exec", "nginx -t -q -p "+prefix+"/ -c "+prefix+"/etc/nginx/nginx.conf")
+			assert.Contains(files["/etc/nginx/nginx.conf"], "include "+prefix+"/etc/nginx/conf.d/*.conf;")
+			assert.Contains(files["/etc/nginx/conf.d/one.example.com.conf"], "ssl_certificate "+prefix+"/etc/nginx/ssl/one.example.com.pem;")
+			assert.Equal("cert", files["/etc/nginx/ssl/one.example.com.pem"])
+			assert.Contains(files, "/etc/nginx/mime.types")
+		}
+
+		_, err := os.Stat(prefix)
+		assert.True(os.IsNotExist(err), "sandbox should be removed")
+	})
+
+	t.Run("copies the params files included by backend locations", func(t *testing.T) {
+		tests := map[string]struct {
+			location string
+			file     string
+		}{
+			"FastCGI": {
+				location: "include fastcgi_params;\n        fastcgi_pass default-app-80;",
+				file:     "/etc/nginx/fastcgi_params",
+			},
+			"UWSGI": {
+				location: "include uwsgi_params;\n        uwsgi_pass default-app-80;",
+				file:     "/etc/nginx/uwsgi_params",
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				assert := assert.New(t)
+				var exists bool
+				v := NewSandboxValidator(execFunc(func(cmd string) error {
+					prefix := strings.TrimSuffix(strings.Fields(cmd)[4], "/")
+					_, err := os.Stat(prefix + test.file)
+					exists = err == nil
+					return nil
+				}), baseDir)
+
+				serverConfig := &pb.ServerConfig{
+					Name:   "one.example.com",
+					Config: []byte("server {\n    location / {\n        " + test.location + "\n    }\n}\n"),
+				}
+				if assert.NoError(v.ValidateServerConfig(mainConfig, serverConfig)) {
+					assert.True(exists, "%s should exist in the sandbox", test.file)
+				}
+			})
+		}
+	})
+
+	t.Run("returns a ConfigError with the nginx output", func(t *testing.T) {
+		assert := assert.New(t)
+		v := NewSandboxValidator(execFunc(func(cmd string) error {
+			prefix := strings.TrimSuffix(strings.Fields(cmd)[4], "/")
+			output := `nginx: [emerg] unknown directive "foo" in ` + prefix + "/etc/nginx/conf.d/one.example.com.conf:2\n"
+			return shell.ExecError{
+				Err:    errors.New("exit status 1"),
+				Output: []byte(output),
+			}
+		}), baseDir)
+
+		err := v.ValidateServerConfig(mainConfig, serverConfig)
+		if assert.IsType(&ConfigError{}, err) {
+			cerr := err.(*ConfigError)
+			assert.Equal("one.example.com", cerr.ServerName)
+			assert.Equal(`nginx: [emerg] unknown directive "foo" in /etc/nginx/conf.d/one.example.com.conf:2`, cerr.Output)
+		}
+	})
+
+	t.Run("returns other errors unchanged", func(t *testing.T) {
+		assert := assert.New(t)
+		e := &ExecutorMock{}
+		v := NewSandboxValidator(e, baseDir)
+		execErr := errors.New("test")
+		e.On("Exec", mock.Anything).Return(execErr)
+
+		assert.Equal(execErr, v.ValidateServerConfig(mainConfig, serverConfig))
+	})
+}